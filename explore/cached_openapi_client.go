@@ -2,8 +2,13 @@ package explore
 
 import (
 	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
 	"sync"
 
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/openapi"
 )
 
@@ -11,11 +16,21 @@ type cachedOpenAPIClient struct {
 	pool *sync.Pool
 }
 
-func newCachedOpenAPIClient(c openapi.Client) (openapi.Client, error) {
+// newCachedOpenAPIClient memoizes the paths of the client.
+// If dir is not empty, the documents of the group versions are also stored
+// under dir and reused by later invocations until their hash changes.
+func newCachedOpenAPIClient(c openapi.Client, dir string) (openapi.Client, error) {
 	paths, err := c.Paths()
 	if err != nil {
 		return nil, err
 	}
+	if dir != "" {
+		cached := make(map[string]openapi.GroupVersion, len(paths))
+		for p, gv := range paths {
+			cached[p] = newDiskCachedGroupVersion(gv, filepath.Join(dir, filepath.FromSlash(p)))
+		}
+		paths = cached
+	}
 	p := &sync.Pool{
 		New: func() interface{} {
 			return paths
@@ -34,3 +49,96 @@ func (c *cachedOpenAPIClient) Paths() (map[string]openapi.GroupVersion, error) {
 	}
 	return paths, nil
 }
+
+// diskCachedGroupVersion stores the JSON document of the group version as
+// <dir>/<hash>.json, where hash is the discovery etag the API server puts in
+// the server relative URL. A new hash means the document has changed,
+// so the stale files are removed when the new document is stored.
+type diskCachedGroupVersion struct {
+	openapi.GroupVersion
+	dir  string
+	hash string
+}
+
+func newDiskCachedGroupVersion(gv openapi.GroupVersion, dir string) openapi.GroupVersion {
+	u, err := url.Parse(gv.ServerRelativeURL())
+	if err != nil {
+		return gv
+	}
+	hash := u.Query().Get("hash")
+	if !cacheKeyRegex.MatchString(hash) {
+		// The document cannot be invalidated without the hash.
+		return gv
+	}
+	return &diskCachedGroupVersion{
+		GroupVersion: gv,
+		dir:          dir,
+		hash:         hash,
+	}
+}
+
+var cacheKeyRegex = regexp.MustCompile(`^[0-9A-Za-z_.-]+$`)
+
+func (g *diskCachedGroupVersion) Schema(contentType string) ([]byte, error) {
+	if contentType != runtime.ContentTypeJSON {
+		return g.GroupVersion.Schema(contentType)
+	}
+	file := filepath.Join(g.dir, g.hash+".json")
+	if b, err := os.ReadFile(file); err == nil {
+		return b, nil
+	}
+	b, err := g.GroupVersion.Schema(contentType)
+	if err != nil {
+		return nil, err
+	}
+	// Failing to write the cache must not fail the explanation.
+	_ = g.store(file, b)
+	return b, nil
+}
+
+func (g *diskCachedGroupVersion) store(file string, b []byte) error {
+	if err := os.MkdirAll(g.dir, 0o755); err != nil {
+		return err
+	}
+	stale, err := filepath.Glob(filepath.Join(g.dir, "*.json"))
+	if err != nil {
+		return err
+	}
+	for _, f := range stale {
+		os.Remove(f)
+	}
+	tmp, err := os.CreateTemp(g.dir, g.hash+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(b); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), file)
+}
+
+// defaultCacheDir returns kubectl-explore/<host> under the cache directory of
+// the user, e.g. $XDG_CACHE_HOME on Linux, or an empty string if there is none.
+func defaultCacheDir(host string) string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "kubectl-explore", cacheDirName(host))
+}
+
+var cacheDirNameRegex = regexp.MustCompile(`[^0-9A-Za-z_.-]`)
+
+// cacheDirName converts the host of the API server into a directory name
+// in the same manner as the discovery cache of kubectl.
+func cacheDirName(host string) string {
+	if u, err := url.Parse(host); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	return cacheDirNameRegex.ReplaceAllString(host, "_")
+}
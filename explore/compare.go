@@ -0,0 +1,190 @@
+package explore
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/spf13/cobra"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/genericclioptions"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+type CompareOptions struct {
+	*Options
+
+	// User input
+	resource string
+	contexts []string
+
+	// After completion
+	gvr   schema.GroupVersionResource
+	sides []*Options
+
+	// Dependencies
+	newFactory func(context string) cmdutil.Factory
+}
+
+func NewCompareOptions(o *Options) *CompareOptions {
+	return &CompareOptions{Options: o}
+}
+
+func newCompareCmd(o *Options, kubeConfigFlags *genericclioptions.ConfigFlags) *cobra.Command {
+	c := NewCompareOptions(o)
+	c.newFactory = func(context string) cmdutil.Factory {
+		return cmdutil.NewFactory(cmdutil.NewMatchVersionFlags(withContext(kubeConfigFlags, context)))
+	}
+	cmd := &cobra.Command{
+		Use:   "compare RESOURCE --context CONTEXT --context CONTEXT",
+		Short: "Show the fields of a resource that exist only in one of two clusters.",
+		Example: `
+# Show the fields of Deployment that exist only in one of the clusters.
+kubectl explore compare deployments --context a --context b
+
+# Compare a specific API version.
+kubectl explore compare flowschemas --api-version=flowcontrol.apiserver.k8s.io/v1 --context a --context b
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			cmdutil.CheckErr(c.Complete(args))
+			cmdutil.CheckErr(c.Run())
+		},
+	}
+	// Shadow the --context flag of kubectl to take two contexts.
+	cmd.Flags().StringArrayVar(&c.contexts, "context", c.contexts, "The name of the kubeconfig context to compare. Specify exactly twice")
+	cmd.Flags().StringVar(&o.apiVersion, "api-version", o.apiVersion, "Compare particular API version (API group/version). Defaults to the preferred version of the first context")
+	return cmd
+}
+
+// withContext returns a copy of the flags with the context replaced, so that
+// both sides share --namespace, --user, --server and the other flags.
+// The clients cached by the flags are not copied because they are bound to
+// the context.
+func withContext(f *genericclioptions.ConfigFlags, context string) *genericclioptions.ConfigFlags {
+	flags := defaultConfigFlags()
+	flags.CacheDir = f.CacheDir
+	flags.KubeConfig = f.KubeConfig
+	flags.ClusterName = f.ClusterName
+	flags.AuthInfoName = f.AuthInfoName
+	flags.Context = &context
+	flags.Namespace = f.Namespace
+	flags.APIServer = f.APIServer
+	flags.TLSServerName = f.TLSServerName
+	flags.Insecure = f.Insecure
+	flags.CertFile = f.CertFile
+	flags.KeyFile = f.KeyFile
+	flags.CAFile = f.CAFile
+	flags.BearerToken = f.BearerToken
+	flags.Impersonate = f.Impersonate
+	flags.ImpersonateUID = f.ImpersonateUID
+	flags.ImpersonateGroup = f.ImpersonateGroup
+	flags.Username = f.Username
+	flags.Password = f.Password
+	flags.Timeout = f.Timeout
+	flags.DisableCompression = f.DisableCompression
+	flags.WrapConfigFn = f.WrapConfigFn
+	return flags
+}
+
+func (c *CompareOptions) Complete(args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("exactly one resource is required")
+	}
+	if len(c.contexts) != 2 {
+		return fmt.Errorf("--context must be specified exactly twice, got %d", len(c.contexts))
+	}
+	c.resource = args[0]
+	c.sides = make([]*Options, len(c.contexts))
+	for i, context := range c.contexts {
+		side := NewOptions(c.IOStreams)
+		side.apiVersion = c.apiVersion
+		side.showBrackets = c.showBrackets
+		side.refreshCache = c.refreshCache
+		if err := side.completeDependencies(c.newFactory(context)); err != nil {
+			return fmt.Errorf("context %s: %w", context, err)
+		}
+		c.sides[i] = side
+	}
+
+	// Use the same resource on both sides so that the schemas are comparable,
+	// taking it from the first context serving it.
+	var errs []error
+	for i, side := range c.sides {
+		gvr, err := c.resolve(side)
+		if err == nil {
+			c.gvr = gvr
+			return nil
+		}
+		errs = append(errs, fmt.Errorf("context %s: %w", c.contexts[i], err))
+	}
+	return errors.Join(errs...)
+}
+
+// resolve returns the resource of the API version, or the preferred one if
+// no API version is specified.
+func (c *CompareOptions) resolve(side *Options) (schema.GroupVersionResource, error) {
+	if c.apiVersion != "" {
+		return side.resourceFor(c.apiVersion, c.resource)
+	}
+	gvarMap, _, err := side.discover()
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	gvar, ok := gvarMap[c.resource]
+	if !ok {
+		return schema.GroupVersionResource{}, fmt.Errorf("no resource found for %s", c.resource)
+	}
+	return gvar.GroupVersionResource, nil
+}
+
+func (c *CompareOptions) Run() error {
+	visitors := make([]*schemaVisitor, len(c.sides))
+	var missing []string
+	for i, side := range c.sides {
+		ok, err := side.serves(c.gvr)
+		if err != nil {
+			return fmt.Errorf("context %s: %w", c.contexts[i], err)
+		}
+		if !ok {
+			missing = append(missing, c.contexts[i])
+			continue
+		}
+		v, err := side.visit(c.gvr)
+		if err != nil {
+			return fmt.Errorf("context %s: %w", c.contexts[i], err)
+		}
+		visitors[i] = v
+	}
+	fmt.Fprintf(c.Out, "RESOURCE: %s\n", c.gvr)
+	if len(missing) > 0 {
+		// The resource only served by one side is the largest difference.
+		for _, context := range missing {
+			fmt.Fprintf(c.Out, "MISSING IN %s\n", context)
+		}
+		return nil
+	}
+	return printPathDiffs(c.Out, diffPaths(visitors[0], visitors[1]), c.showBrackets, map[pathDiffKind]string{
+		pathRemoved:     fmt.Sprintf("ONLY IN %s", c.contexts[0]),
+		pathAdded:       fmt.Sprintf("ONLY IN %s", c.contexts[1]),
+		pathTypeChanged: fmt.Sprintf("TYPE DIFFERS (%s -> %s)", c.contexts[0], c.contexts[1]),
+	})
+}
+
+// serves reports whether the API server serves the resource, which may be
+// missing in a cluster of another version or without a CustomResourceDefinition.
+func (o *Options) serves(gvr schema.GroupVersionResource) (bool, error) {
+	list, err := o.discovery.ServerResourcesForGroupVersion(gvr.GroupVersion().String())
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	for _, r := range list.APIResources {
+		if r.Name == gvr.Resource {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,237 @@
+package explore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilyaml "k8s.io/apimachinery/pkg/util/yaml"
+	"sigs.k8s.io/yaml"
+)
+
+// customResourceDefinition is the part of apiextensions.k8s.io/v1 CustomResourceDefinition
+// needed to explore custom resources before they are installed.
+type customResourceDefinition struct {
+	APIVersion string `json:"apiVersion"`
+	Kind       string `json:"kind"`
+	Spec       struct {
+		Group string `json:"group"`
+		Names struct {
+			Plural     string   `json:"plural"`
+			Singular   string   `json:"singular"`
+			Kind       string   `json:"kind"`
+			ShortNames []string `json:"shortNames"`
+			Categories []string `json:"categories"`
+		} `json:"names"`
+		Scope    string                            `json:"scope"`
+		Versions []customResourceDefinitionVersion `json:"versions"`
+	} `json:"spec"`
+}
+
+type customResourceDefinitionVersion struct {
+	Name               string `json:"name"`
+	Served             bool   `json:"served"`
+	Deprecated         bool   `json:"deprecated"`
+	DeprecationWarning string `json:"deprecationWarning"`
+	Schema             *struct {
+		OpenAPIV3Schema map[string]interface{} `json:"openAPIV3Schema"`
+	} `json:"schema"`
+}
+
+// readManifests reads YAML or JSON documents from the files.
+// Directories are walked recursively and files other than .yaml, .yml and .json are ignored.
+func readManifests(filenames []string) ([][]byte, error) {
+	var manifests [][]byte
+	for _, filename := range filenames {
+		err := filepath.WalkDir(filename, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				return nil
+			}
+			if p != filename && !slices.Contains([]string{".yaml", ".yml", ".json"}, filepath.Ext(p)) {
+				return nil
+			}
+			f, err := os.Open(p)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			r := utilyaml.NewYAMLReader(bufio.NewReader(f))
+			for {
+				b, err := r.Read()
+				if errors.Is(err, io.EOF) {
+					return nil
+				}
+				if err != nil {
+					return fmt.Errorf("read %s: %w", p, err)
+				}
+				if len(bytes.TrimSpace(b)) == 0 {
+					continue
+				}
+				j, err := yaml.YAMLToJSON(b)
+				if err != nil {
+					return fmt.Errorf("parse %s: %w", p, err)
+				}
+				if string(j) == "null" {
+					continue
+				}
+				manifests = append(manifests, j)
+			}
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return manifests, nil
+}
+
+// parseCRD returns nil if the manifest is not a CustomResourceDefinition.
+func parseCRD(manifest []byte) (*customResourceDefinition, error) {
+	var crd customResourceDefinition
+	if err := json.Unmarshal(manifest, &crd); err != nil {
+		return nil, err
+	}
+	if crd.Kind != "CustomResourceDefinition" || !strings.HasPrefix(crd.APIVersion, "apiextensions.k8s.io/") {
+		return nil, nil
+	}
+	if crd.APIVersion != "apiextensions.k8s.io/v1" {
+		return nil, fmt.Errorf("unsupported CustomResourceDefinition version: %s", crd.APIVersion)
+	}
+	return &crd, nil
+}
+
+// crdDocuments synthesizes an OpenAPI v3 document per group version and the
+// API resources from the CustomResourceDefinitions, as the API server does
+// when they are installed.
+func crdDocuments(crds []*customResourceDefinition) (map[schema.GroupVersion][]byte, []*metav1.APIResourceList, error) {
+	docs := make(map[schema.GroupVersion]openAPIV3Document)
+	lists := make(map[schema.GroupVersion]*metav1.APIResourceList)
+	var gvs []schema.GroupVersion
+	for _, crd := range crds {
+		names := crd.Spec.Names
+		singular := names.Singular
+		if singular == "" {
+			singular = strings.ToLower(names.Kind)
+		}
+		namespaced := crd.Spec.Scope == "Namespaced"
+		for _, v := range crd.Spec.Versions {
+			if !v.Served || v.Schema == nil || v.Schema.OpenAPIV3Schema == nil {
+				continue
+			}
+			gv := schema.GroupVersion{Group: crd.Spec.Group, Version: v.Name}
+			gvk := gv.WithKind(names.Kind)
+			doc, ok := docs[gv]
+			if !ok {
+				doc = openAPIV3Document{
+					"openapi": "3.0.0",
+					"info": map[string]interface{}{
+						"title":   "Kubernetes CRD Swagger",
+						"version": "v0.1.0",
+					},
+					"paths": map[string]interface{}{},
+					"components": map[string]interface{}{
+						"schemas": map[string]interface{}{},
+					},
+				}
+				docs[gv] = doc
+				lists[gv] = &metav1.APIResourceList{GroupVersion: gv.String()}
+				gvs = append(gvs, gv)
+			}
+			gvkExtension := map[string]interface{}{
+				"group":   gvk.Group,
+				"version": gvk.Version,
+				"kind":    gvk.Kind,
+			}
+			definition := make(map[string]interface{}, len(v.Schema.OpenAPIV3Schema)+1)
+			for k, val := range v.Schema.OpenAPIV3Schema {
+				definition[k] = val
+			}
+			definition["type"] = "object"
+			if _, ok := definition["properties"]; !ok {
+				definition["properties"] = map[string]interface{}{}
+			}
+			definition["x-kubernetes-group-version-kind"] = []interface{}{gvkExtension}
+			doc.schemas()[crdDefinitionName(gvk)] = definition
+
+			resourcePath := "/" + groupVersionPath(gv) + "/" + names.Plural
+			if namespaced {
+				resourcePath = "/" + groupVersionPath(gv) + "/namespaces/{namespace}/" + names.Plural
+			}
+			doc["paths"].(map[string]interface{})[resourcePath] = map[string]interface{}{
+				"get": map[string]interface{}{
+					"responses": map[string]interface{}{
+						"200": map[string]interface{}{
+							"description": "OK",
+						},
+					},
+					"x-kubernetes-group-version-kind": gvkExtension,
+				},
+			}
+			lists[gv].APIResources = append(lists[gv].APIResources, metav1.APIResource{
+				Name:         names.Plural,
+				SingularName: singular,
+				Namespaced:   namespaced,
+				Kind:         names.Kind,
+				ShortNames:   names.ShortNames,
+				Categories:   names.Categories,
+			})
+		}
+	}
+	documents := make(map[schema.GroupVersion][]byte, len(docs))
+	resourceLists := make([]*metav1.APIResourceList, 0, len(lists))
+	for _, gv := range gvs {
+		b, err := json.Marshal(docs[gv])
+		if err != nil {
+			return nil, nil, err
+		}
+		documents[gv] = b
+		resourceLists = append(resourceLists, lists[gv])
+	}
+	return documents, resourceLists, nil
+}
+
+// crdDefinitionName returns the definition name the API server publishes for
+// a custom resource, e.g. com.example.stable.v1.CronTab.
+func crdDefinitionName(gvk schema.GroupVersionKind) string {
+	segments := strings.Split(gvk.Group, ".")
+	slices.Reverse(segments)
+	return strings.Join(append(segments, gvk.Version, gvk.Kind), ".")
+}
+
+// loadManifests loads the CustomResourceDefinitions in the files, and returns
+// the other manifests as they are, to be parsed only by the commands using them.
+func loadManifests(filenames []string) ([]*customResourceDefinition, [][]byte, error) {
+	manifests, err := readManifests(filenames)
+	if err != nil {
+		return nil, nil, err
+	}
+	var crds []*customResourceDefinition
+	var others [][]byte
+	for _, manifest := range manifests {
+		crd, err := parseCRD(manifest)
+		if err != nil {
+			return nil, nil, err
+		}
+		if crd != nil {
+			crds = append(crds, crd)
+			continue
+		}
+		others = append(others, manifest)
+	}
+	if len(crds) == 0 && len(others) == 0 {
+		return nil, nil, fmt.Errorf("no CustomResourceDefinition or manifest found in %s", strings.Join(filenames, ", "))
+	}
+	return crds, others, nil
+}
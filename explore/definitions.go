@@ -0,0 +1,306 @@
+package explore
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/ktr0731/go-fuzzyfinder"
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/util/proto"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+type DefinitionsOptions struct {
+	*Options
+
+	// User input
+	inputDefinition string
+
+	// After completion
+	definitionRegex *regexp.Regexp
+}
+
+func NewDefinitionsOptions(o *Options) *DefinitionsOptions {
+	return &DefinitionsOptions{Options: o}
+}
+
+func newDefinitionsCmd(o *Options, f cmdutil.Factory) *cobra.Command {
+	d := NewDefinitionsOptions(o)
+	cmd := &cobra.Command{
+		Use:   "definitions [regex]",
+		Short: "Fuzzy-find an OpenAPI definition, such as one not served as a resource, and then the field of it to explain.",
+		Example: `
+# Fuzzy-find a definition among all of them, and then its field.
+kubectl explore definitions
+
+# Fuzzy-find the fields of a definition only embedded in resources.
+kubectl explore definitions 'io.k8s.api.core.v1.ObjectReference$'
+
+# Fuzzy-find among the definitions matching a regex, case-insensitively.
+kubectl explore definitions selector
+
+# Print the field of a definition in JSON.
+kubectl explore definitions -o json ObjectReference
+`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			cmdutil.CheckErr(d.Complete(f, args))
+			cmdutil.CheckErr(d.Run())
+		},
+	}
+	cmd.Flags().BoolVar(&o.disablePrintPath, "disable-print-path", o.disablePrintPath, "Disable printing the path to explain")
+	cmd.Flags().StringVarP(&o.output, "output", "o", o.output, fmt.Sprintf("Output format of the explanation. One of: %s", strings.Join(definitionOutputFormats, "|")))
+	cmd.Flags().StringSliceVarP(&o.filenames, "filename", "f", o.filenames, "Explore the definitions of CustomResourceDefinitions in the files or directories instead of a cluster")
+	return cmd
+}
+
+// definitionOutputFormats are the output formats of the definitions subcommand,
+// excluding those printing a manifest or a command for a resource.
+var definitionOutputFormats = []string{
+	outputPlaintext,
+	outputJSON,
+	outputYAML,
+}
+
+func (d *DefinitionsOptions) Complete(f cmdutil.Factory, args []string) error {
+	if !slices.Contains(definitionOutputFormats, d.output) {
+		return fmt.Errorf("unsupported output format %q, must be one of: %s", d.output, strings.Join(definitionOutputFormats, "|"))
+	}
+	d.inputDefinition = ".*"
+	if len(args) > 0 {
+		d.inputDefinition = args[0]
+	}
+	var err error
+	// Definition names mix cases, e.g. LabelSelector, so they are matched case-insensitively.
+	d.definitionRegex, err = regexp.Compile("(?i)" + d.inputDefinition)
+	if err != nil {
+		return err
+	}
+	return d.completeDependencies(f)
+}
+
+func (d *DefinitionsOptions) Run() error {
+	definitions, err := d.schema.definitions()
+	if err != nil {
+		return err
+	}
+	var names []string
+	for name := range definitions {
+		if d.definitionRegex.MatchString(name) {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return fmt.Errorf("no definitions found for %q", d.inputDefinition)
+	}
+	sort.Strings(names)
+	// The explainer synthesizes a document, so it is built once per definition
+	// rather than every time the preview is drawn.
+	explainers := make(map[string]definitionExplainer)
+	explainerOf := func(name string) (definitionExplainer, error) {
+		if e, ok := explainers[name]; ok {
+			return e, nil
+		}
+		e, err := d.definitionExplainer(name, definitions[name])
+		if err != nil {
+			return definitionExplainer{}, err
+		}
+		explainers[name] = e
+		return e, nil
+	}
+	name := names[0]
+	if len(names) > 1 {
+		idx, err := fuzzyfinder.Find(names, func(i int) string { return names[i] },
+			fuzzyfinder.WithPreviewWindow(func(i, _, _ int) string {
+				if i < 0 {
+					return ""
+				}
+				e, err := explainerOf(names[i])
+				if err != nil {
+					return fmt.Sprintf("preview is broken: %s", err)
+				}
+				var w bytes.Buffer
+				if err := e.explain(&w, e.rootPath()); err != nil {
+					return fmt.Sprintf("preview is broken: %s", err)
+				}
+				return w.String()
+			}))
+		if err != nil {
+			return err
+		}
+		name = names[idx]
+	}
+
+	e, err := explainerOf(name)
+	if err != nil {
+		return err
+	}
+	visitor := &schemaVisitor{
+		prevPath:   e.rootPath(),
+		pathSchema: make(map[path]proto.Schema),
+		references: make(map[string][]path),
+		required:   make(map[path]bool),
+		mapPaths:   make(map[string]bool),
+	}
+	e.schema.Accept(visitor)
+	if visitor.err != nil {
+		return visitor.err
+	}
+	paths := append([]path{e.rootPath()}, visitor.listPaths(func(path) bool { return true })...)
+	if len(paths) == 1 {
+		return e.print(d.Out, paths[0])
+	}
+	idx, err := fuzzyfinder.Find(paths, func(i int) string {
+		if d.showBrackets {
+			return paths[i].withBrackets
+		}
+		return paths[i].original
+	}, fuzzyfinder.WithPreviewWindow(func(i, _, _ int) string {
+		if i < 0 {
+			return ""
+		}
+		var w bytes.Buffer
+		if err := e.explain(&w, paths[i]); err != nil {
+			return fmt.Sprintf("preview is broken: %s", err)
+		}
+		return w.String()
+	}))
+	if err != nil {
+		return err
+	}
+	return e.print(d.Out, paths[idx])
+}
+
+func (d *DefinitionsOptions) definitionExplainer(name string, def definition) (definitionExplainer, error) {
+	documents, gvr, err := definitionDocuments(def.doc, name)
+	if err != nil {
+		return definitionExplainer{}, err
+	}
+	source, err := newOfflineSource(documents, nil)
+	if err != nil {
+		return definitionExplainer{}, err
+	}
+	return definitionExplainer{
+		name:   name,
+		schema: def.schema,
+		explainer: explainer{
+			gvr:                 gvr,
+			openAPIV3Client:     source,
+			documents:           newOpenAPIV3Documents(source),
+			enablePrintPath:     !d.disablePrintPath,
+			enablePrintBrackets: d.showBrackets,
+			outputFormat:        d.output,
+		},
+	}, nil
+}
+
+// definitionResource is the resource a definition is served as by the
+// document synthesized by definitionDocuments.
+const definitionResource = "definitions"
+
+// definitionDocuments synthesizes a document serving the definition as a
+// resource, so that it is explained the same way as resources. The kind of
+// the definition is its own if it is a kind, and that in its name otherwise.
+// The other definitions stay in the document to resolve the references, but
+// are untagged so that none of them is taken for the kind.
+func definitionDocuments(doc openAPIV3Document, name string) (map[schema.GroupVersion][]byte, schema.GroupVersionResource, error) {
+	gvk := definitionGVK(name)
+	schemas := make(map[string]interface{}, len(doc.schemas()))
+	for n, v := range doc.schemas() {
+		s, ok := v.(map[string]interface{})
+		if !ok {
+			schemas[n] = v
+			continue
+		}
+		s = maps.Clone(s)
+		gvks, _ := s["x-kubernetes-group-version-kind"].([]interface{})
+		delete(s, "x-kubernetes-group-version-kind")
+		if n == name {
+			if len(gvks) > 0 {
+				if got, ok := parseGroupVersionKind(gvks[0]); ok {
+					gvk = got
+				}
+			}
+			s["x-kubernetes-group-version-kind"] = []interface{}{gvkExtension(gvk)}
+		}
+		schemas[n] = s
+	}
+	gvr := gvk.GroupVersion().WithResource(definitionResource)
+	synthesized := openAPIV3Document{
+		"openapi": doc["openapi"],
+		"info":    doc["info"],
+		"paths": map[string]interface{}{
+			"/" + groupVersionPath(gvr.GroupVersion()) + "/" + definitionResource: map[string]interface{}{
+				"get": map[string]interface{}{
+					"x-kubernetes-group-version-kind": gvkExtension(gvk),
+				},
+			},
+		},
+		"components": map[string]interface{}{
+			"schemas": schemas,
+		},
+	}
+	b, err := json.Marshal(synthesized)
+	if err != nil {
+		return nil, schema.GroupVersionResource{}, err
+	}
+	return map[schema.GroupVersion][]byte{gvr.GroupVersion(): b}, gvr, nil
+}
+
+func gvkExtension(gvk schema.GroupVersionKind) map[string]interface{} {
+	return map[string]interface{}{
+		"group":   gvk.Group,
+		"version": gvk.Version,
+		"kind":    gvk.Kind,
+	}
+}
+
+// definitionExplainer explains the fields of a definition, which has no
+// resource to be explained by kubectl explain. The paths of the fields start
+// with the last segment of the name, e.g. ObjectReference.fieldPath.
+type definitionExplainer struct {
+	name   string
+	schema proto.Schema
+	explainer
+}
+
+func (e definitionExplainer) rootPath() path {
+	kind := e.name[strings.LastIndex(e.name, ".")+1:]
+	return path{original: kind, withBrackets: kind}
+}
+
+func (e definitionExplainer) explain(w io.Writer, p path) error {
+	fmt.Fprintf(w, "DEFINITION: %s\n", e.name)
+	return e.explainer.explain(w, p)
+}
+
+// print writes the explanation in the output format, where the plaintext
+// one tells the definition.
+func (e definitionExplainer) print(w io.Writer, p path) error {
+	if e.outputFormat == outputPlaintext {
+		return e.explain(w, p)
+	}
+	return e.explainer.print(w, p)
+}
+
+var versionRegex = regexp.MustCompile(`^v\d+((alpha|beta)\d+)?$`)
+
+// definitionGVK returns the kind and the version in the name of the
+// definition, e.g. ObjectReference and v1 for io.k8s.api.core.v1.ObjectReference.
+// The version is empty if the name has none, e.g. io.k8s.apimachinery.pkg.util.intstr.IntOrString.
+func definitionGVK(name string) schema.GroupVersionKind {
+	segments := strings.Split(name, ".")
+	gvk := schema.GroupVersionKind{Kind: segments[len(segments)-1]}
+	if len(segments) > 1 && versionRegex.MatchString(segments[len(segments)-2]) {
+		gvk.Version = segments[len(segments)-2]
+	}
+	return gvk
+}
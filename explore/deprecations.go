@@ -0,0 +1,277 @@
+package explore
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+type DeprecationsOptions struct {
+	*Options
+}
+
+func NewDeprecationsOptions(o *Options) *DeprecationsOptions {
+	return &DeprecationsOptions{Options: o}
+}
+
+func newDeprecationsCmd(o *Options, f cmdutil.Factory) *cobra.Command {
+	d := NewDeprecationsOptions(o)
+	cmd := &cobra.Command{
+		Use:   "deprecations",
+		Short: "Report the deprecated API versions and fields, with the versions replacing them.",
+		Example: `
+# Report the deprecations of the resources in the cluster to plan an upgrade.
+kubectl explore deprecations
+
+# Report the deprecations of CustomResourceDefinitions without a cluster.
+kubectl explore deprecations -f ./config/crd/bases/
+`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			cmdutil.CheckErr(d.Complete(f))
+			cmdutil.CheckErr(d.Run())
+		},
+	}
+	cmd.Flags().StringSliceVarP(&o.filenames, "filename", "f", o.filenames, "Report the deprecations of CustomResourceDefinitions in the files or directories instead of a cluster")
+	return cmd
+}
+
+func (d *DeprecationsOptions) Complete(f cmdutil.Factory) error {
+	if err := d.completeDependencies(f); err != nil {
+		return err
+	}
+	if d.openAPIDir != "" || len(d.filenames) > 0 {
+		return nil
+	}
+	crds, err := listCustomResourceDefinitions(f)
+	if err != nil {
+		// The deprecations found through discovery are still worth reporting
+		// if the user cannot list CustomResourceDefinitions.
+		fmt.Fprintf(d.ErrOut, "Warning: skip the deprecated versions of CustomResourceDefinitions: %s\n", err)
+		return nil
+	}
+	d.crds = crds
+	return nil
+}
+
+// listCustomResourceDefinitions lists the CustomResourceDefinitions in the cluster.
+func listCustomResourceDefinitions(f cmdutil.Factory) ([]*customResourceDefinition, error) {
+	client, err := f.DynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	list, err := client.Resource(schema.GroupVersionResource{
+		Group:    "apiextensions.k8s.io",
+		Version:  "v1",
+		Resource: "customresourcedefinitions",
+	}).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	crds := make([]*customResourceDefinition, 0, len(list.Items))
+	for _, item := range list.Items {
+		b, err := json.Marshal(item.Object)
+		if err != nil {
+			return nil, err
+		}
+		var crd customResourceDefinition
+		if err := json.Unmarshal(b, &crd); err != nil {
+			return nil, fmt.Errorf("decode %s: %w", item.GetName(), err)
+		}
+		crds = append(crds, &crd)
+	}
+	return crds, nil
+}
+
+// deprecatedVersion is a resource served in a deprecated API version.
+type deprecatedVersion struct {
+	gvr schema.GroupVersionResource
+	// replacement is the version to migrate to, or empty if there is none.
+	replacement schema.GroupVersion
+	// warning is the deprecation warning of a CustomResourceDefinition.
+	warning string
+	// inferred tells that the version is not marked as deprecated but is a
+	// prerelease version older than the preferred one.
+	inferred bool
+}
+
+// deprecatedField is a field described as deprecated.
+type deprecatedField struct {
+	gvr  schema.GroupVersionResource
+	path path
+	note string
+}
+
+func (d *DeprecationsOptions) Run() error {
+	gvrs, err := d.listGVRs()
+	if err != nil {
+		return err
+	}
+	versions, err := d.deprecatedVersions(gvrs)
+	if err != nil {
+		return err
+	}
+	var fields []deprecatedField
+	for _, gvr := range gvrs {
+		visitor, err := d.visit(gvr)
+		if err != nil {
+			return fmt.Errorf("%s: %w", gvr, err)
+		}
+		for _, p := range visitor.listPaths(func(p path) bool { return isDeprecated(visitor.pathSchema[p]) }) {
+			fields = append(fields, deprecatedField{
+				gvr:  gvr,
+				path: p,
+				note: deprecationNote(schemaDescription(visitor.pathSchema[p])),
+			})
+		}
+	}
+	printDeprecations(d.Out, versions, fields, d.showBrackets)
+	return nil
+}
+
+// deprecatedVersions finds the resources served in a prerelease version older
+// than their preferred version, and in the versions of CustomResourceDefinitions
+// marked as deprecated. The former are inferred because discovery does not tell
+// which versions are deprecated.
+func (d *DeprecationsOptions) deprecatedVersions(preferred []schema.GroupVersionResource) ([]deprecatedVersion, error) {
+	preferredVersions := make(map[schema.GroupResource]schema.GroupVersion, len(preferred))
+	for _, gvr := range preferred {
+		preferredVersions[gvr.GroupResource()] = gvr.GroupVersion()
+	}
+	// The OpenAPI v3 documents are served for all the group versions,
+	// while discovery lists the preferred ones.
+	paths, err := d.cachedOpenAPIV3Client.Paths()
+	if err != nil {
+		return nil, err
+	}
+	var gvs []schema.GroupVersion
+	for p := range paths {
+		if gv, ok := parseGroupVersionPath(p); ok {
+			gvs = append(gvs, gv)
+		}
+	}
+	found := make(map[schema.GroupVersionResource]*deprecatedVersion)
+	for _, gv := range gvs {
+		if !isPrerelease(gv.Version) {
+			continue
+		}
+		list, err := d.discovery.ServerResourcesForGroupVersion(gv.String())
+		if err != nil {
+			continue
+		}
+		for _, l := range filterOutSubresources(list) {
+			for _, r := range l.APIResources {
+				gvr := gv.WithResource(r.Name)
+				replacement, ok := preferredVersions[gvr.GroupResource()]
+				if ok && version.CompareKubeAwareVersionStrings(replacement.Version, gv.Version) > 0 {
+					found[gvr] = &deprecatedVersion{gvr: gvr, replacement: replacement, inferred: true}
+				}
+			}
+		}
+	}
+	for _, crd := range d.crds {
+		var replacement schema.GroupVersion
+		for _, v := range crd.Spec.Versions {
+			if !v.Served || v.Deprecated {
+				continue
+			}
+			if replacement.Version == "" || version.CompareKubeAwareVersionStrings(v.Name, replacement.Version) > 0 {
+				replacement = schema.GroupVersion{Group: crd.Spec.Group, Version: v.Name}
+			}
+		}
+		for _, v := range crd.Spec.Versions {
+			if !v.Served || !v.Deprecated {
+				continue
+			}
+			gvr := schema.GroupVersionResource{Group: crd.Spec.Group, Version: v.Name, Resource: crd.Spec.Names.Plural}
+			warning := v.DeprecationWarning
+			if warning == "" {
+				// The default warning of the API server.
+				warning = fmt.Sprintf("%s/%s %s is deprecated", crd.Spec.Group, v.Name, crd.Spec.Names.Kind)
+			}
+			found[gvr] = &deprecatedVersion{gvr: gvr, replacement: replacement, warning: warning}
+		}
+	}
+	versions := make([]deprecatedVersion, 0, len(found))
+	for _, v := range found {
+		versions = append(versions, *v)
+	}
+	slices.SortFunc(versions, func(a, b deprecatedVersion) int {
+		return strings.Compare(a.gvr.String(), b.gvr.String())
+	})
+	return versions, nil
+}
+
+var prereleaseRegex = regexp.MustCompile(`^v\d+(alpha|beta)\d+$`)
+
+// isPrerelease reports whether the version is an alpha or a beta, e.g. v2beta2.
+// A GA version such as autoscaling/v1 stays supported after a newer one is preferred.
+func isPrerelease(v string) bool {
+	return prereleaseRegex.MatchString(v)
+}
+
+// deprecationNote returns the sentences of the description mentioning the
+// deprecation, which often name the field replacing it.
+func deprecationNote(description string) string {
+	var sentences []string
+	// A line break ends a sentence too, e.g. after a URL without a period,
+	// so the lines are split before the whitespace is collapsed.
+	for _, line := range strings.Split(description, "\n") {
+		// A sentence ends with ". " rather than "." so as not to split URLs.
+		for _, s := range strings.SplitAfter(strings.Join(strings.Fields(line), " "), ". ") {
+			if deprecatedRegex.MatchString(s) {
+				sentences = append(sentences, strings.TrimSpace(s))
+			}
+		}
+	}
+	return strings.Join(sentences, " ")
+}
+
+func printDeprecations(w io.Writer, versions []deprecatedVersion, fields []deprecatedField, showBrackets bool) {
+	if len(versions) == 0 && len(fields) == 0 {
+		fmt.Fprintln(w, "No deprecations found.")
+		return
+	}
+	if len(versions) > 0 {
+		fmt.Fprintln(w, "DEPRECATED API VERSIONS:")
+		for _, v := range versions {
+			replacement := "no replacement"
+			if v.replacement.Version != "" {
+				replacement = "use " + v.replacement.String()
+			}
+			fmt.Fprintf(w, "  %s %s\t%s\n", v.gvr.GroupVersion(), v.gvr.Resource, replacement)
+			if v.warning != "" {
+				fmt.Fprintf(w, "    %s\n", v.warning)
+			}
+			if v.inferred {
+				fmt.Fprintf(w, "    inferred: a prerelease version older than the preferred %s\n", v.replacement)
+			}
+		}
+	}
+	if len(fields) > 0 {
+		if len(versions) > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, "DEPRECATED FIELDS:")
+		for _, f := range fields {
+			p := f.path.original
+			if showBrackets {
+				p = f.path.withBrackets
+			}
+			fmt.Fprintf(w, "  %s\t%s\n", p, f.gvr.GroupVersion())
+			if f.note != "" {
+				fmt.Fprintf(w, "    %s\n", f.note)
+			}
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package explore
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/util/proto"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/explain"
+)
+
+type DiffOptions struct {
+	*Options
+
+	// User input
+	resource string
+	from     string
+	to       string
+
+	// After completion
+	fromGVR schema.GroupVersionResource
+	toGVR   schema.GroupVersionResource
+}
+
+func NewDiffOptions(o *Options) *DiffOptions {
+	return &DiffOptions{Options: o}
+}
+
+func newDiffCmd(o *Options, f cmdutil.Factory) *cobra.Command {
+	d := NewDiffOptions(o)
+	cmd := &cobra.Command{
+		Use:   "diff RESOURCE --from API_VERSION --to API_VERSION",
+		Short: "Show the fields added, removed and changed between two API versions of a resource.",
+		Example: `
+# Show what changed in HorizontalPodAutoscaler from autoscaling/v1 to autoscaling/v2.
+kubectl explore diff hpa --from autoscaling/v1 --to autoscaling/v2
+
+# Compare the API versions in OpenAPI v3 documents on disk without a cluster.
+kubectl explore diff flowschemas --from flowcontrol.apiserver.k8s.io/v1beta3 --to flowcontrol.apiserver.k8s.io/v1 --openapi-dir=./kubernetes/api/openapi-spec/v3
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			cmdutil.CheckErr(d.Complete(f, args))
+			cmdutil.CheckErr(d.Run())
+		},
+	}
+	cmd.Flags().StringVar(&d.from, "from", d.from, "API version to compare from (API group/version)")
+	cmd.Flags().StringVar(&d.to, "to", d.to, "API version to compare to (API group/version)")
+	cmd.Flags().StringSliceVarP(&o.filenames, "filename", "f", o.filenames, "Compare CustomResourceDefinitions in the files or directories instead of a cluster")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+	return cmd
+}
+
+func (d *DiffOptions) Complete(f cmdutil.Factory, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("exactly one resource is required")
+	}
+	if d.from == "" || d.to == "" {
+		return fmt.Errorf("both --from and --to are required")
+	}
+	d.resource = args[0]
+	if err := d.completeDependencies(f); err != nil {
+		return err
+	}
+	var err error
+	d.fromGVR, err = d.resourceFor(d.from, d.resource)
+	if err != nil {
+		return err
+	}
+	d.toGVR, err = d.resourceFor(d.to, d.resource)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func (d *DiffOptions) Run() error {
+	from, err := d.visit(d.fromGVR)
+	if err != nil {
+		return err
+	}
+	to, err := d.visit(d.toGVR)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(d.Out, "FROM: %s\n", d.fromGVR.GroupVersion())
+	fmt.Fprintf(d.Out, "TO:   %s\n", d.toGVR.GroupVersion())
+	return printPathDiffs(d.Out, diffPaths(from, to), d.showBrackets, map[pathDiffKind]string{
+		pathRemoved:     "REMOVED",
+		pathAdded:       "ADDED",
+		pathTypeChanged: "TYPE CHANGED",
+	})
+}
+
+type pathDiffKind int
+
+const (
+	pathRemoved pathDiffKind = iota
+	pathAdded
+	pathTypeChanged
+)
+
+// pathDiff is a path existing on either side, or a path whose type differs.
+type pathDiff struct {
+	kind pathDiffKind
+	path path
+	from proto.Schema
+	to   proto.Schema
+}
+
+// diffPaths compares the paths collected from two schemas of a resource.
+// A path added or removed together with its parent is not reported because
+// the parent already covers it.
+func diffPaths(from, to *schemaVisitor) []pathDiff {
+	fromSchemas := make(map[string]proto.Schema, len(from.pathSchema))
+	for p, s := range from.pathSchema {
+		fromSchemas[p.original] = s
+	}
+	toSchemas := make(map[string]proto.Schema, len(to.pathSchema))
+	for p, s := range to.pathSchema {
+		toSchemas[p.original] = s
+	}
+	var diffs []pathDiff
+	for p, s := range from.pathSchema {
+		if _, ok := toSchemas[p.original]; ok {
+			continue
+		}
+		if _, ok := toSchemas[parentPath(p.original)]; !ok && fromSchemas[parentPath(p.original)] != nil {
+			continue
+		}
+		diffs = append(diffs, pathDiff{kind: pathRemoved, path: p, from: s})
+	}
+	for p, s := range to.pathSchema {
+		fromSchema, ok := fromSchemas[p.original]
+		if !ok {
+			if _, ok := fromSchemas[parentPath(p.original)]; !ok && toSchemas[parentPath(p.original)] != nil {
+				continue
+			}
+			diffs = append(diffs, pathDiff{kind: pathAdded, path: p, to: s})
+			continue
+		}
+		if explain.GetTypeName(fromSchema) != explain.GetTypeName(s) {
+			diffs = append(diffs, pathDiff{kind: pathTypeChanged, path: p, from: fromSchema, to: s})
+		}
+	}
+	sort.SliceStable(diffs, func(i, j int) bool {
+		return diffs[i].path.original < diffs[j].path.original
+	})
+	return diffs
+}
+
+func parentPath(p string) string {
+	if i := strings.LastIndex(p, "."); i >= 0 {
+		return p[:i]
+	}
+	return ""
+}
+
+// printPathDiffs prints the diffs grouped by their kind under the headings,
+// with the description of each path.
+func printPathDiffs(w io.Writer, diffs []pathDiff, showBrackets bool, headings map[pathDiffKind]string) error {
+	if len(diffs) == 0 {
+		_, err := fmt.Fprintln(w, "\nNo differences found.")
+		return err
+	}
+	for _, kind := range []pathDiffKind{pathRemoved, pathAdded, pathTypeChanged} {
+		var section []pathDiff
+		for _, diff := range diffs {
+			if diff.kind == kind {
+				section = append(section, diff)
+			}
+		}
+		if len(section) == 0 {
+			continue
+		}
+		fmt.Fprintf(w, "\n%s:\n", headings[kind])
+		for _, diff := range section {
+			p := diff.path.original
+			if showBrackets {
+				p = diff.path.withBrackets
+			}
+			switch diff.kind {
+			case pathRemoved:
+				fmt.Fprintf(w, "  %s\t<%s>\n", p, explain.GetTypeName(diff.from))
+				printDescription(w, diff.from)
+			case pathAdded:
+				fmt.Fprintf(w, "  %s\t<%s>\n", p, explain.GetTypeName(diff.to))
+				printDescription(w, diff.to)
+			case pathTypeChanged:
+				fmt.Fprintf(w, "  %s\t<%s> -> <%s>\n", p, explain.GetTypeName(diff.from), explain.GetTypeName(diff.to))
+				printDescription(w, diff.to)
+			}
+		}
+	}
+	return nil
+}
+
+func printDescription(w io.Writer, s proto.Schema) {
+	for _, line := range strings.Split(schemaDescription(s), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			fmt.Fprintf(w, "    %s\n", line)
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package explore
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/explain"
+)
+
+type DocsOptions struct {
+	*Options
+
+	// User input
+	out string
+}
+
+func NewDocsOptions(o *Options) *DocsOptions {
+	return &DocsOptions{Options: o}
+}
+
+func newDocsCmd(o *Options, f cmdutil.Factory) *cobra.Command {
+	d := NewDocsOptions(o)
+	cmd := &cobra.Command{
+		Use:   "docs --out DIRECTORY",
+		Short: "Write a Markdown page with the fields of each API resource and an index of them.",
+		Example: `
+# Write the reference of the API resources in the cluster.
+kubectl explore docs --out ./site
+
+# Write the reference of CustomResourceDefinitions without a cluster.
+kubectl explore docs --out ./docs/reference -f ./config/crd/bases/
+`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			cmdutil.CheckErr(d.Complete(f))
+			cmdutil.CheckErr(d.Run())
+		},
+	}
+	cmd.Flags().StringVar(&d.out, "out", d.out, "Directory to write the pages to")
+	cmd.Flags().StringSliceVarP(&o.filenames, "filename", "f", o.filenames, "Document CustomResourceDefinitions in the files or directories instead of a cluster")
+	_ = cmd.MarkFlagRequired("out")
+	return cmd
+}
+
+func (d *DocsOptions) Complete(f cmdutil.Factory) error {
+	if d.out == "" {
+		return fmt.Errorf("--out is required")
+	}
+	return d.completeDependencies(f)
+}
+
+func (d *DocsOptions) Run() error {
+	_, gvrs, err := d.discover()
+	if err != nil {
+		return err
+	}
+	var pages []docsPage
+	for _, gvr := range gvrs {
+		page, err := d.writePage(gvr)
+		if err != nil {
+			return fmt.Errorf("%s: %w", gvr, err)
+		}
+		pages = append(pages, page)
+	}
+	var index bytes.Buffer
+	writeDocsIndex(&index, pages)
+	if err := os.WriteFile(filepath.Join(d.out, "index.md"), index.Bytes(), 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(d.Out, "Wrote %d pages and index.md to %s\n", len(pages), d.out)
+	return nil
+}
+
+// docsPage is a page written for a resource.
+type docsPage struct {
+	gvr  schema.GroupVersionResource
+	kind string
+	// file is the path of the page relative to the output directory,
+	// e.g. apps/v1/deployments.md.
+	file string
+}
+
+func (d *DocsOptions) writePage(gvr schema.GroupVersionResource) (docsPage, error) {
+	gvk, err := d.mapper.KindFor(gvr)
+	if err != nil {
+		return docsPage{}, fmt.Errorf("get the group version kind: %w", err)
+	}
+	root, err := d.resourceSchema(gvr)
+	if err != nil {
+		return docsPage{}, err
+	}
+	visitor, err := d.visit(gvr)
+	if err != nil {
+		return docsPage{}, err
+	}
+	group := gvr.Group
+	if group == "" {
+		group = coreGroup
+	}
+	page := docsPage{
+		gvr:  gvr,
+		kind: gvk.Kind,
+		file: filepath.Join(group, gvr.Version, gvr.Resource+".md"),
+	}
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "# %s\n\n", gvk.Kind)
+	fmt.Fprintf(&b, "- Group: `%s`\n", group)
+	fmt.Fprintf(&b, "- Version: `%s`\n", gvr.Version)
+	fmt.Fprintf(&b, "- Resource: `%s`\n\n", gvr.Resource)
+	if desc := markdownCell(root.GetDescription()); desc != "" {
+		fmt.Fprintf(&b, "%s\n\n", desc)
+	}
+	writeFieldTable(&b, visitor, d.showBrackets)
+
+	file := filepath.Join(d.out, page.file)
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		return docsPage{}, err
+	}
+	if err := os.WriteFile(file, b.Bytes(), 0o644); err != nil {
+		return docsPage{}, err
+	}
+	return page, nil
+}
+
+// writeFieldTable writes the fields of the resource in the order of their
+// paths, so that the fields of an object follow it.
+func writeFieldTable(w io.Writer, visitor *schemaVisitor, showBrackets bool) {
+	fmt.Fprintln(w, "| Path | Type | Required | Description |")
+	fmt.Fprintln(w, "| --- | --- | --- | --- |")
+	for _, p := range visitor.listPaths(func(path) bool { return true }) {
+		required := ""
+		if visitor.required[p] {
+			required = "yes"
+		}
+		// Drop the resource name, which is the same in all the rows.
+		fieldPath := p.original
+		if showBrackets {
+			fieldPath = p.withBrackets
+		}
+		_, fieldPath, _ = strings.Cut(fieldPath, ".")
+		fmt.Fprintf(w, "| `%s` | `%s` | %s | %s |\n",
+			fieldPath,
+			explain.GetTypeName(visitor.pathSchema[p]),
+			required,
+			markdownCell(schemaDescription(visitor.pathSchema[p])),
+		)
+	}
+}
+
+// markdownCell puts the text in a line and escapes the characters that
+// would break a table or be taken as HTML.
+func markdownCell(text string) string {
+	text = strings.Join(strings.Fields(text), " ")
+	return strings.NewReplacer("|", `\|`, "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// writeDocsIndex writes the links to the pages grouped by API group.
+func writeDocsIndex(w io.Writer, pages []docsPage) {
+	slices.SortStableFunc(pages, func(a, b docsPage) int {
+		return strings.Compare(a.file, b.file)
+	})
+	fmt.Fprintln(w, "# API Resources")
+	var group string
+	for i, page := range pages {
+		if g := strings.Split(page.file, string(filepath.Separator))[0]; i == 0 || g != group {
+			group = g
+			fmt.Fprintf(w, "\n## %s\n\n", group)
+		}
+		fmt.Fprintf(w, "- [%s](%s) (`%s`, %s)\n", page.kind, filepath.ToSlash(page.file), page.gvr.Resource, page.gvr.Version)
+	}
+}
@@ -0,0 +1,186 @@
+package explore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ktr0731/go-fuzzyfinder"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// drillUpLabel goes back to the parent level in the drill-down mode.
+	drillUpLabel = ".."
+	// drillPrintLabel prints the field of the current level in the drill-down mode.
+	drillPrintLabel = "."
+)
+
+// drillLevel is a field the finder is scoped to in the drill-down mode.
+type drillLevel struct {
+	path path
+	explainer
+}
+
+// drillDown re-opens the finder scoped to the children of the selected
+// field as long as it has children, and goes back up with ".." or Esc.
+type drillDown struct {
+	o *Options
+	// children indexes the paths of each resource by their parents.
+	children map[schema.GroupVersionResource]map[string][]path
+}
+
+func newDrillDown(o *Options, visitors map[schema.GroupVersionResource]*schemaVisitor) *drillDown {
+	d := &drillDown{
+		o:        o,
+		children: make(map[schema.GroupVersionResource]map[string][]path, len(visitors)),
+	}
+	for gvr, visitor := range visitors {
+		all := make(map[string]path, len(visitor.pathSchema))
+		for p := range visitor.pathSchema {
+			all[p.original] = p
+		}
+		// The paths are filtered as in Run except by the regex, and the fields
+		// leading to them are kept to drill down through.
+		indexed := make(map[string]bool)
+		index := make(map[string][]path)
+		for _, p := range visitor.listPaths(o.setOnlyFilter(gvr, visitor)) {
+			for ; p.original != "" && !indexed[p.original]; p = all[parentPath(p.original)] {
+				indexed[p.original] = true
+				parent := parentPath(p.original)
+				index[parent] = append(index[parent], p)
+			}
+		}
+		for _, children := range index {
+			slices.SortFunc(children, func(a, b path) int {
+				return strings.Compare(a.original, b.original)
+			})
+		}
+		d.children[gvr] = index
+	}
+	return d
+}
+
+func (d *drillDown) hasChildren(level drillLevel) bool {
+	return len(d.children[level.gvr][level.path.original]) > 0
+}
+
+// run starts from the paths, or from the only path if it has children.
+// label and preview are those of the paths.
+func (d *drillDown) run(pathExplainers map[path]explainer, paths []path, label func(int) string, preview fuzzyfinder.Option) error {
+	var levels []drillLevel
+	if len(paths) == 1 {
+		var selected *drillLevel
+		levels, selected = d.enter(levels, drillLevel{path: paths[0], explainer: pathExplainers[paths[0]]})
+		if selected != nil {
+			return selected.print(d.o.Out, selected.path)
+		}
+	}
+	for {
+		if len(levels) == 0 {
+			idx, err := fuzzyfinder.Find(paths, label, preview,
+				fuzzyfinder.WithHeader("Enter: drill down into an object, or print a field"))
+			if err != nil {
+				return err
+			}
+			var selected *drillLevel
+			levels, selected = d.enter(levels, drillLevel{path: paths[idx], explainer: pathExplainers[paths[idx]]})
+			if selected != nil {
+				return selected.print(d.o.Out, selected.path)
+			}
+			continue
+		}
+
+		current := levels[len(levels)-1]
+		items, children := d.items(current)
+		idx, err := fuzzyfinder.Find(items, func(i int) string { return items[i] },
+			fuzzyfinder.WithHeader(d.breadcrumb(current)+"\nEnter: drill down, ..: go up, .: print this field, Esc: go up"),
+			fuzzyfinder.WithPreviewWindow(func(i, _, _ int) string {
+				var p path
+				switch {
+				case i < 0:
+					return ""
+				case i == 0:
+					return fmt.Sprintf("Go up to %s", d.breadcrumb(drillLevel{path: path{original: parentPath(current.path.original)}}))
+				case i == 1:
+					p = current.path
+				default:
+					p = children[i-2]
+				}
+				var w bytes.Buffer
+				if err := current.explain(&w, p); err != nil {
+					return fmt.Sprintf("preview is broken: %s", err)
+				}
+				return w.String()
+			}))
+		if errors.Is(err, fuzzyfinder.ErrAbort) {
+			// Esc goes up like "..".
+			idx, err = 0, nil
+		}
+		if err != nil {
+			return err
+		}
+		var selected *drillLevel
+		levels, selected = d.selectItem(levels, children, idx)
+		if selected != nil {
+			return selected.print(d.o.Out, selected.path)
+		}
+	}
+}
+
+// items returns the items of the finder scoped to the level, ".." and "."
+// followed by its children marked by whether they have children, and the
+// paths of the children.
+func (d *drillDown) items(level drillLevel) ([]string, []path) {
+	children := d.children[level.gvr][level.path.original]
+	items := append([]string{drillUpLabel, drillPrintLabel}, make([]string, len(children))...)
+	for i, child := range children {
+		marker := treeMarkerLeaf
+		if d.hasChildren(drillLevel{path: child, explainer: level.explainer}) {
+			marker = treeMarkerCollapsed
+		}
+		items[i+2] = marker + child.original[len(level.path.original)+1:]
+	}
+	return items, children
+}
+
+// enter pushes the level to the levels if it has children. Otherwise, it
+// returns the level to print.
+func (d *drillDown) enter(levels []drillLevel, level drillLevel) ([]drillLevel, *drillLevel) {
+	if !d.hasChildren(level) {
+		return levels, &level
+	}
+	return append(levels, level), nil
+}
+
+// selectItem applies the item selected in the finder scoped to the last level.
+// ".." pops the level, "." returns it to print, and a child is entered.
+func (d *drillDown) selectItem(levels []drillLevel, children []path, idx int) ([]drillLevel, *drillLevel) {
+	current := levels[len(levels)-1]
+	switch idx {
+	case 0:
+		return levels[:len(levels)-1], nil
+	case 1:
+		return levels, &current
+	default:
+		return d.enter(levels, drillLevel{path: children[idx-2], explainer: current.explainer})
+	}
+}
+
+// breadcrumb returns the fields from the resource to the level,
+// e.g. deployments › spec › template.
+func (d *drillDown) breadcrumb(level drillLevel) string {
+	return breadcrumb(level.path, d.o.showBrackets)
+}
+
+// breadcrumb returns the fields from the resource to the path,
+// e.g. deployments › spec › template.
+func breadcrumb(p path, showBrackets bool) string {
+	s := p.original
+	if showBrackets && p.withBrackets != "" {
+		s = p.withBrackets
+	}
+	return strings.ReplaceAll(s, ".", " › ")
+}
@@ -1,20 +1,51 @@
 package explore
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	openapiclient "k8s.io/client-go/openapi"
+	"k8s.io/kube-openapi/pkg/util/proto"
+	"k8s.io/kubectl/pkg/explain"
 	explainv2 "k8s.io/kubectl/pkg/explain/v2"
+	"sigs.k8s.io/yaml"
 )
 
+const (
+	outputPlaintext = "plaintext"
+	outputJSON      = "json"
+	outputYAML      = "yaml"
+	outputSkeleton  = "skeleton"
+	// The output formats below print a command querying the field of live objects.
+	outputJSONPath      = "jsonpath"
+	outputCustomColumns = "custom-columns"
+	outputJQ            = "jq"
+)
+
+var outputFormats = []string{
+	outputPlaintext,
+	outputJSON,
+	outputYAML,
+	outputSkeleton,
+	outputJSONPath,
+	outputCustomColumns,
+	outputJQ,
+}
+
 type explainer struct {
 	gvr                 schema.GroupVersionResource
 	openAPIV3Client     openapiclient.Client
+	documents           *openAPIV3Documents
 	enablePrintPath     bool
 	enablePrintBrackets bool
+	outputFormat        string
+	// object is the live object to show the values of, if any.
+	object map[string]interface{}
+	// mapPaths is the set of the paths of the map fields of the resource.
+	mapPaths map[string]bool
 }
 
 func (e explainer) explain(w io.Writer, path path) error {
@@ -33,12 +64,340 @@ func (e explainer) explain(w io.Writer, path path) error {
 			w.Write([]byte(fmt.Sprintf("PATH: %s\n", path.original)))
 		}
 	}
-	return explainv2.PrintModelDescription(
+	if e.object != nil {
+		if err := printLiveValue(w, e.object, path, e.mapPaths); err != nil {
+			return err
+		}
+	}
+	if err := explainv2.PrintModelDescription(
 		fields,
 		w,
 		e.openAPIV3Client,
 		e.gvr,
 		false,
 		"plaintext",
-	)
+	); err != nil {
+		return err
+	}
+	return e.printSchemaSections(w, fields)
+}
+
+// schemaExtensions are the extensions printed after the explanation, which
+// tell how a list or an object is merged by a patch or validated.
+var schemaExtensions = []string{
+	"x-kubernetes-list-type",
+	"x-kubernetes-list-map-keys",
+	"x-kubernetes-patch-strategy",
+	"x-kubernetes-patch-merge-key",
+	"x-kubernetes-int-or-string",
+	"x-kubernetes-preserve-unknown-fields",
+	"x-kubernetes-embedded-resource",
+}
+
+// schemaConstraints are the keywords constraining the value of a field.
+var schemaConstraints = []string{
+	"format",
+	"minimum",
+	"maximum",
+	"minLength",
+	"maxLength",
+	"pattern",
+	"minItems",
+	"maxItems",
+}
+
+// printSchemaSections prints the extensions, the value constraints and the
+// CEL validation rules of the field, and those of its items, if any.
+func (e explainer) printSchemaSections(w io.Writer, fields []string) error {
+	if e.documents == nil {
+		return nil
+	}
+	doc, _, field, err := e.rootSchema()
+	if err != nil {
+		return err
+	}
+	if len(fields) > 0 {
+		if field, _, err = doc.lookupField(field, fields); err != nil {
+			return err
+		}
+	}
+	printKeywordSection(w, "EXTENSIONS", schemaExtensions, doc.keywords(field, schemaExtensions))
+	printKeywordSection(w, "CONSTRAINTS", schemaConstraints, doc.keywords(field, schemaConstraints))
+	printValidationSection(w, "VALIDATIONS", doc.validationRules(field))
+	// The items of an array or the values of a map, e.g. tags[] with maxLength.
+	if items := doc.itemSchema(field); items != nil {
+		printKeywordSection(w, "ITEM CONSTRAINTS", schemaConstraints, doc.keywords(items, schemaConstraints))
+		printValidationSection(w, "ITEM VALIDATIONS", doc.validationRules(items))
+	}
+	return nil
+}
+
+// printValidationSection prints the CEL validation rules under the heading,
+// if any.
+func printValidationSection(w io.Writer, heading string, rules []validationRule) {
+	if len(rules) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s:\n", heading)
+	for _, r := range rules {
+		fmt.Fprintf(w, "  %s\n", r.Rule)
+		if r.Message != "" {
+			fmt.Fprintf(w, "    %s\n", r.Message)
+		} else if r.MessageExpression != "" {
+			fmt.Fprintf(w, "    messageExpression: %s\n", r.MessageExpression)
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// printKeywordSection prints the keywords found in the order of names under the heading.
+func printKeywordSection(w io.Writer, heading string, names []string, keywords map[string]interface{}) {
+	if len(keywords) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "%s:\n", heading)
+	for _, name := range names {
+		if v, ok := keywords[name]; ok {
+			fmt.Fprintf(w, "  %s\t%s\n", name, keywordValue(v))
+		}
+	}
+	fmt.Fprintln(w)
+}
+
+// keywordValue formats the value of a keyword, e.g. [containerPort, protocol].
+func keywordValue(v interface{}) string {
+	items, ok := v.([]interface{})
+	if !ok {
+		return fmt.Sprint(v)
+	}
+	values := make([]string, len(items))
+	for i, item := range items {
+		values[i] = fmt.Sprint(item)
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+// rootSchema returns the document of the resource and the definition of its kind.
+func (e explainer) rootSchema() (openAPIV3Document, schema.GroupVersionKind, map[string]interface{}, error) {
+	doc, err := e.documents.get(e.gvr.GroupVersion())
+	if err != nil {
+		return nil, schema.GroupVersionKind{}, nil, err
+	}
+	gvk, err := doc.kindFor(e.gvr)
+	if err != nil {
+		return nil, schema.GroupVersionKind{}, nil, err
+	}
+	root, err := doc.lookupKind(gvk)
+	if err != nil {
+		return nil, schema.GroupVersionKind{}, nil, err
+	}
+	return doc, gvk, root, nil
+}
+
+// print writes the explanation of the path in the output format.
+func (e explainer) print(w io.Writer, path path) error {
+	switch e.outputFormat {
+	case "", outputPlaintext:
+		return e.explain(w, path)
+	case outputJSON:
+		f, err := e.describe(path)
+		if err != nil {
+			return err
+		}
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		// Keep the operators of validation rules readable, e.g. <=.
+		enc.SetEscapeHTML(false)
+		return enc.Encode(f)
+	case outputYAML:
+		f, err := e.describe(path)
+		if err != nil {
+			return err
+		}
+		b, err := yaml.Marshal(f)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(b)
+		return err
+	case outputSkeleton:
+		s, err := e.skeleton()
+		if err != nil {
+			return err
+		}
+		if err := s.add(path); err != nil {
+			return err
+		}
+		return s.encode(w)
+	case outputJSONPath, outputCustomColumns, outputJQ:
+		command, err := queryCommand(e.gvr, path, e.mapPaths, e.outputFormat)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintln(w, command)
+		return err
+	default:
+		return fmt.Errorf("unsupported output format: %s", e.outputFormat)
+	}
+}
+
+// fieldDescription is the structured explanation of a field.
+type fieldDescription struct {
+	Name             string                 `json:"name"`
+	Path             string                 `json:"path"`
+	PathWithBrackets string                 `json:"pathWithBrackets"`
+	Group            string                 `json:"group,omitempty"`
+	Version          string                 `json:"version"`
+	Kind             string                 `json:"kind"`
+	Type             string                 `json:"type"`
+	Description      string                 `json:"description,omitempty"`
+	Required         bool                   `json:"required"`
+	Enum             []interface{}          `json:"enum,omitempty"`
+	Default          interface{}            `json:"default,omitempty"`
+	Constraints      map[string]interface{} `json:"constraints,omitempty"`
+	Validations      []validationRule       `json:"validations,omitempty"`
+	ItemConstraints  map[string]interface{} `json:"itemConstraints,omitempty"`
+	ItemValidations  []validationRule       `json:"itemValidations,omitempty"`
+	Fields           []childDescription     `json:"fields,omitempty"`
+	Value            interface{}            `json:"value,omitempty"`
+}
+
+// childDescription is the summary of a field directly under the described field.
+type childDescription struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required"`
+}
+
+func (e explainer) describe(path path) (*fieldDescription, error) {
+	if path.isEmpty() {
+		return nil, fmt.Errorf("path must not be empty: %#v", path)
+	}
+	fields := strings.Split(path.original, ".")[1:]
+	doc, gvk, root, err := e.rootSchema()
+	if err != nil {
+		return nil, err
+	}
+	// The path without fields describes the kind itself, e.g. the root of a definition.
+	field, parent, err := doc.lookupField(root, fields)
+	if err != nil {
+		return nil, err
+	}
+	name := gvk.Kind
+	if len(fields) > 0 {
+		name = fields[len(fields)-1]
+	}
+	resolved := doc.resolve(field)
+	d := &fieldDescription{
+		Name:             name,
+		Path:             path.original,
+		PathWithBrackets: path.withBrackets,
+		Group:            gvk.Group,
+		Version:          gvk.Version,
+		Kind:             gvk.Kind,
+		Type:             doc.typeName(field),
+		Description:      doc.description(field),
+		Required:         required(parent, name),
+		Default:          field["default"],
+		Constraints:      doc.keywords(field, schemaConstraints),
+		Validations:      doc.validationRules(field),
+	}
+	d.Enum = doc.enum(field)
+	if items := doc.itemSchema(field); items != nil {
+		d.ItemConstraints = doc.keywords(items, schemaConstraints)
+		d.ItemValidations = doc.validationRules(items)
+	}
+	if d.Default == nil {
+		d.Default = resolved["default"]
+	}
+	if e.object != nil {
+		d.Value, _ = liveValue(e.object, path, e.mapPaths)
+	}
+	if object := doc.objectSchema(field); object != nil {
+		properties, _ := object["properties"].(map[string]interface{})
+		for _, childName := range propertyNames(object) {
+			child, _ := properties[childName].(map[string]interface{})
+			d.Fields = append(d.Fields, childDescription{
+				Name:        childName,
+				Type:        doc.typeName(child),
+				Description: doc.description(child),
+				Required:    required(object, childName),
+			})
+		}
+	}
+	return d, nil
+}
+
+// fieldSummary returns the type of the field in the schema stored by the visitor,
+// and its default and enum in the document, e.g. <string> [Always|IfNotPresent|Never].
+// root is the definition of the kind of the resource in the document. The type
+// alone is returned if root is nil or the field is not found under it.
+func fieldSummary(doc openAPIV3Document, root map[string]interface{}, p path, s proto.Schema) string {
+	summary := "<" + explain.GetTypeName(s) + ">"
+	fields := strings.Split(p.original, ".")[1:]
+	if len(fields) == 0 || root == nil {
+		return summary
+	}
+	field, _, err := doc.lookupField(root, fields)
+	if err != nil {
+		return summary
+	}
+	def, ok := field["default"]
+	if !ok {
+		def = doc.resolve(field)["default"]
+	}
+	// An empty string or object is the zero value the API server sets on
+	// most fields, so it is not worth the space in the label.
+	switch v := def.(type) {
+	case nil:
+	case string:
+		if v != "" {
+			summary += " default=" + v
+		}
+	case map[string]interface{}:
+		if len(v) > 0 {
+			b, _ := json.Marshal(v)
+			summary += " default=" + string(b)
+		}
+	default:
+		b, _ := json.Marshal(v)
+		summary += " default=" + string(b)
+	}
+	if enum := doc.enum(field); len(enum) > 0 {
+		values := make([]string, len(enum))
+		for i, v := range enum {
+			values[i] = fmt.Sprint(v)
+		}
+		summary += " [" + strings.Join(values, "|") + "]"
+	}
+	return summary
+}
+
+func (e explainer) skeleton() (*skeleton, error) {
+	doc, err := e.documents.get(e.gvr.GroupVersion())
+	if err != nil {
+		return nil, err
+	}
+	gvk, err := doc.kindFor(e.gvr)
+	if err != nil {
+		return nil, err
+	}
+	return newSkeleton(doc, gvk)
+}
+
+// template writes a manifest template merging the paths, where each field
+// is commented with its type and the first sentence of its description.
+func (e explainer) template(w io.Writer, paths []path) error {
+	s, err := e.skeleton()
+	if err != nil {
+		return err
+	}
+	s.annotate = true
+	for _, p := range paths {
+		if err := s.add(p); err != nil {
+			return err
+		}
+	}
+	return s.encode(w)
 }
@@ -1,5 +1,15 @@
 package explore
 
+import (
+	"io"
+	"net/http"
+	"slices"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/util/proto"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
 func SetDisablePrintPath(o *Options, b bool) {
 	o.disablePrintPath = b
 }
@@ -11,3 +21,221 @@ func SetShowBrackets(o *Options, b bool) {
 func SetAPIVersion(o *Options, apiVersion string) {
 	o.apiVersion = apiVersion
 }
+
+func SetOutput(o *Options, output string) {
+	o.output = output
+}
+
+func SetOpenAPIDir(o *Options, dir string) {
+	o.openAPIDir = dir
+}
+
+func SetFilenames(o *Options, filenames []string) {
+	o.filenames = filenames
+}
+
+func SetCacheDir(o *Options, dir string) {
+	o.cacheDir = dir
+}
+
+func SetRefreshCache(o *Options, b bool) {
+	o.refreshCache = b
+}
+
+func SetDiffFrom(o *DiffOptions, apiVersion string) {
+	o.from = apiVersion
+}
+
+func SetDiffTo(o *DiffOptions, apiVersion string) {
+	o.to = apiVersion
+}
+
+func SetCompareContexts(o *CompareOptions, contexts []string) {
+	o.contexts = contexts
+}
+
+func SetCompareFactory(o *CompareOptions, newFactory func(context string) cmdutil.Factory) {
+	o.newFactory = newFactory
+}
+
+var WithContext = withContext
+
+func SetSearchDescriptions(o *Options, b bool) {
+	o.searchDescriptions = b
+}
+
+func SetMulti(o *Options, b bool) {
+	o.multi = b
+}
+
+func SetSetOnly(o *Options, b bool) {
+	o.setOnly = b
+}
+
+func SetTree(o *Options, b bool) {
+	o.tree = b
+}
+
+func SetDrillDown(o *Options, b bool) {
+	o.drillDown = b
+}
+
+func SetRequiredOnly(o *Options, b bool) {
+	o.filter.requiredOnly = b
+}
+
+func SetFieldType(o *Options, t string) {
+	o.filter.fieldType = t
+}
+
+func SetHasEnum(o *Options, b bool) {
+	o.filter.hasEnum = b
+}
+
+func SetDeprecated(o *Options, b bool) {
+	o.filter.deprecated = b
+}
+
+func SetReadOnly(o *Options, b bool) {
+	o.filter.readOnly = b
+}
+
+func ServeHandler(o *ServeOptions) http.Handler {
+	return o.handler()
+}
+
+func SetDocsOut(o *DocsOptions, out string) {
+	o.out = out
+}
+
+func SetShowDetails(o *Options, b bool) {
+	o.showDetails = b
+}
+
+// FieldTree builds the tree of the paths and returns the labels of the
+// visible nodes after expanding the nodes at the expanded paths.
+func FieldTree(paths []string, expandedPaths []string) []string {
+	ps := make([]path, len(paths))
+	for i, p := range paths {
+		ps[i] = path{original: p, withBrackets: p}
+	}
+	roots := buildFieldTree(map[path]explainer{}, ps)
+	expanded := make(map[*fieldNode]bool)
+	var walk func([]*fieldNode)
+	walk = func(nodes []*fieldNode) {
+		for _, n := range nodes {
+			expanded[n] = slices.Contains(expandedPaths, n.path.original)
+			walk(n.children)
+		}
+	}
+	walk(roots)
+	var labels []string
+	for _, n := range visibleFieldNodes(roots, expanded) {
+		labels = append(labels, n.label(expanded[n]))
+	}
+	return labels
+}
+
+// TreeSelect selects the items of the tree of the paths in order. It returns
+// the items shown for each selection, and the path to print, which is empty
+// if none is printed.
+func TreeSelect(paths []string, selections []int) ([][]string, string) {
+	ps := make([]path, len(paths))
+	for i, p := range paths {
+		ps[i] = path{original: p, withBrackets: p}
+	}
+	t := newFieldTree(buildFieldTree(map[path]explainer{}, ps))
+	var shown [][]string
+	for _, idx := range selections {
+		items, nodes := t.items()
+		shown = append(shown, items)
+		if n := t.selectItem(nodes, idx); n != nil {
+			return shown, n.path.original
+		}
+	}
+	return shown, ""
+}
+
+// DrillDown drills down from the start path of a resource with the items
+// selected in order. It returns the items of each level shown, and the path
+// to print, which is empty if the selections go back up to the paths.
+// The paths are restricted to the required paths with --required-only if any.
+func DrillDown(paths []string, requiredPaths []string, start string, selections []int) ([][]string, string) {
+	visitor := &schemaVisitor{
+		pathSchema: make(map[path]proto.Schema),
+		required:   make(map[path]bool),
+		filter:     fieldFilter{requiredOnly: len(requiredPaths) > 0},
+	}
+	for _, p := range paths {
+		visitor.pathSchema[path{original: p, withBrackets: p}] = nil
+	}
+	for _, p := range requiredPaths {
+		visitor.required[path{original: p, withBrackets: p}] = true
+	}
+	d := newDrillDown(&Options{}, map[schema.GroupVersionResource]*schemaVisitor{{}: visitor})
+	levels, selected := d.enter(nil, drillLevel{path: path{original: start, withBrackets: start}})
+	var shown [][]string
+	for _, idx := range selections {
+		if selected != nil || len(levels) == 0 {
+			break
+		}
+		items, children := d.items(levels[len(levels)-1])
+		shown = append(shown, items)
+		levels, selected = d.selectItem(levels, children, idx)
+	}
+	if selected == nil {
+		return shown, ""
+	}
+	return shown, selected.path.original
+}
+
+// PrintDefinition prints the path of the definition as the definitions
+// subcommand does once the definition and the path are selected.
+func PrintDefinition(o *DefinitionsOptions, name, original, withBrackets string) error {
+	definitions, err := o.schema.definitions()
+	if err != nil {
+		return err
+	}
+	e, err := o.definitionExplainer(name, definitions[name])
+	if err != nil {
+		return err
+	}
+	return e.print(o.Out, path{original: original, withBrackets: withBrackets})
+}
+
+// FieldSummaries returns the summaries shown with --show-details next to the
+// paths of the resource. Unless documented, the document of its group version
+// is empty as if the kind were missing from it.
+func FieldSummaries(o *Options, gvr schema.GroupVersionResource, documented bool, paths ...string) (map[string]string, error) {
+	visitor, err := o.visit(gvr)
+	if err != nil {
+		return nil, err
+	}
+	documents := newOpenAPIV3Documents(o.cachedOpenAPIV3Client)
+	if !documented {
+		documents.docs[gvr.GroupVersion()] = openAPIV3Document{}
+	}
+	pathExplainers := make(map[path]explainer)
+	var ps []path
+	for _, p := range visitor.listPaths(func(p path) bool { return slices.Contains(paths, p.original) }) {
+		pathExplainers[p] = explainer{gvr: gvr, documents: documents}
+		ps = append(ps, p)
+	}
+	summaries := make(map[string]string)
+	for p, summary := range fieldSummaries(pathExplainers, ps, map[schema.GroupVersionResource]*schemaVisitor{gvr: visitor}) {
+		summaries[p.original] = summary
+	}
+	return summaries, nil
+}
+
+// DeprecationNote returns the sentences of the description telling the
+// deprecation, and whether the description tells one.
+func DeprecationNote(description string) (string, bool) {
+	return deprecationNote(description), deprecatedRegex.MatchString(description)
+}
+
+// PrintInferredDeprecation prints the deprecation of a prerelease version of
+// the resource older than the preferred version.
+func PrintInferredDeprecation(w io.Writer, gvr schema.GroupVersionResource, preferred schema.GroupVersion) {
+	printDeprecations(w, []deprecatedVersion{{gvr: gvr, replacement: preferred, inferred: true}}, nil, false)
+}
@@ -0,0 +1,134 @@
+package explore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/dynamic"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"sigs.k8s.io/yaml"
+)
+
+// populatedMarker marks the paths populated on the object in the fuzzy finder.
+const populatedMarker = "● "
+
+// getObject fetches the object of the resource through the dynamic client.
+func getObject(f cmdutil.Factory, gvar *groupVersionAPIResource, name string) (map[string]interface{}, error) {
+	client, err := f.DynamicClient()
+	if err != nil {
+		return nil, err
+	}
+	var ri dynamic.ResourceInterface = client.Resource(gvar.GroupVersionResource)
+	if gvar.Namespaced {
+		namespace, _, err := f.ToRawKubeConfigLoader().Namespace()
+		if err != nil {
+			return nil, err
+		}
+		ri = client.Resource(gvar.GroupVersionResource).Namespace(namespace)
+	}
+	obj, err := ri.Get(context.TODO(), name, metav1.GetOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get %s/%s: %w", gvar.Resource, name, err)
+	}
+	return obj.Object, nil
+}
+
+// liveValue returns the value at the path in the object.
+// mapPaths is the set of the paths of map fields, whose values are walked like
+// the items of arrays because their keys are not part of the path.
+// If the path goes through arrays or maps, the values of all the items are returned as a list.
+func liveValue(object map[string]interface{}, p path, mapPaths map[string]bool) (interface{}, bool) {
+	segments := querySegments(p, mapPaths)
+	values := []interface{}{object}
+	throughList := false
+	for i, s := range segments {
+		last := i == len(segments)-1
+		var next []interface{}
+		for _, v := range values {
+			m, ok := v.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			child, ok := m[s.name]
+			if !ok || child == nil {
+				continue
+			}
+			children := []interface{}{child}
+			if s.isArray && !last {
+				children, _ = child.([]interface{})
+			}
+			if s.isMap {
+				children = mapValues(children)
+			}
+			next = append(next, children...)
+		}
+		if (s.isArray || s.isMap) && !last {
+			throughList = true
+		}
+		values = next
+	}
+	if len(values) == 0 {
+		return nil, false
+	}
+	if throughList {
+		return values, true
+	}
+	return values[0], true
+}
+
+// mapValues returns the values of the maps in the order of their keys.
+func mapValues(objects []interface{}) []interface{} {
+	var values []interface{}
+	for _, v := range objects {
+		m, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		for _, key := range slices.Sorted(maps.Keys(m)) {
+			values = append(values, m[key])
+		}
+	}
+	return values
+}
+
+// populated reports whether the path is set on any of the objects.
+func populated(objects []map[string]interface{}, p path, mapPaths map[string]bool) bool {
+	for _, object := range objects {
+		if _, ok := liveValue(object, p, mapPaths); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// printLiveValue writes the value at the path in the object in YAML.
+func printLiveValue(w io.Writer, object map[string]interface{}, p path, mapPaths map[string]bool) error {
+	v, ok := liveValue(object, p, mapPaths)
+	if !ok {
+		_, err := fmt.Fprintln(w, "VALUE: <unset>")
+		return err
+	}
+	b, err := yaml.Marshal(v)
+	if err != nil {
+		return err
+	}
+	value := strings.TrimSuffix(string(b), "\n")
+	if !strings.Contains(value, "\n") {
+		_, err := fmt.Fprintf(w, "VALUE: %s\n", value)
+		return err
+	}
+	if _, err := fmt.Fprintln(w, "VALUE:"); err != nil {
+		return err
+	}
+	for _, line := range strings.Split(value, "\n") {
+		if _, err := fmt.Fprintf(w, "  %s\n", line); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,665 @@
+package explore
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/util/proto"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/explain"
+)
+
+type LSPOptions struct {
+	*Options
+}
+
+func NewLSPOptions(o *Options) *LSPOptions {
+	return &LSPOptions{Options: o}
+}
+
+func newLSPCmd(o *Options, f cmdutil.Factory) *cobra.Command {
+	l := NewLSPOptions(o)
+	cmd := &cobra.Command{
+		Use:   "lsp",
+		Short: "Run a Language Server Protocol server for Kubernetes manifests over stdio.",
+		Example: `
+# Run the server for the schema of the current context, e.g. as the command of a YAML language server in an editor.
+kubectl explore lsp
+
+# Serve the schema of CustomResourceDefinitions that are not installed yet.
+kubectl explore lsp -f ./config/crd/bases/
+`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			cmdutil.CheckErr(l.Complete(f))
+			cmdutil.CheckErr(l.Run())
+		},
+	}
+	cmd.Flags().StringSliceVarP(&o.filenames, "filename", "f", o.filenames, "Serve CustomResourceDefinitions in the files or directories instead of a cluster")
+	return cmd
+}
+
+func (l *LSPOptions) Complete(f cmdutil.Factory) error {
+	return l.completeDependencies(f)
+}
+
+func (l *LSPOptions) Run() error {
+	s := &languageServer{
+		o:         l.Options,
+		in:        bufio.NewReader(l.In),
+		out:       l.Out,
+		texts:     make(map[string]string),
+		resources: make(map[schema.GroupVersionKind]*lspResource),
+		documents: newOpenAPIV3Documents(l.cachedOpenAPIV3Client),
+	}
+	return s.serve()
+}
+
+// languageServer serves completion of field keys, hover with the explanation
+// of the field, and diagnostics of unknown fields. The characters of positions
+// are counted in UTF-16 code units as the protocol defines.
+type languageServer struct {
+	o   *Options
+	in  *bufio.Reader
+	out io.Writer
+	// texts are the contents of the open documents by their URI.
+	texts     map[string]string
+	resources map[schema.GroupVersionKind]*lspResource
+	documents *openAPIV3Documents
+}
+
+// lspResource is the schema of a kind found in a document.
+type lspResource struct {
+	gvr  schema.GroupVersionResource
+	root proto.Schema
+	// paths are the valid paths to suggest for unknown fields.
+	paths []path
+}
+
+type rpcMessage struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+const (
+	rpcMethodNotFound = -32601
+	rpcInternalError  = -32603
+)
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspTextDocument struct {
+	URI  string `json:"uri"`
+	Text string `json:"text,omitempty"`
+}
+
+type lspTextDocumentParams struct {
+	TextDocument   lspTextDocument `json:"textDocument"`
+	Position       lspPosition     `json:"position"`
+	ContentChanges []struct {
+		Text string `json:"text"`
+	} `json:"contentChanges"`
+}
+
+type lspCompletionItem struct {
+	Label         string `json:"label"`
+	Kind          int    `json:"kind"`
+	Detail        string `json:"detail,omitempty"`
+	Documentation string `json:"documentation,omitempty"`
+}
+
+// lspCompletionItemKindProperty is CompletionItemKind.Property.
+const lspCompletionItemKindProperty = 10
+
+type lspMarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type lspHover struct {
+	Contents lspMarkupContent `json:"contents"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+const (
+	lspSeverityError   = 1
+	lspSeverityWarning = 2
+)
+
+func (s *languageServer) serve() error {
+	for {
+		msg, err := s.read()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if msg.Method == "exit" {
+			return nil
+		}
+		result, rpcErr := s.handle(msg)
+		if msg.ID == nil {
+			// Notifications have no response.
+			if rpcErr != nil {
+				fmt.Fprintf(s.o.ErrOut, "%s: %s\n", msg.Method, rpcErr.Message)
+			}
+			continue
+		}
+		response := map[string]interface{}{"jsonrpc": "2.0", "id": msg.ID}
+		if rpcErr != nil {
+			response["error"] = rpcErr
+		} else {
+			response["result"] = result
+		}
+		if err := s.write(response); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *languageServer) handle(msg *rpcMessage) (interface{}, *rpcError) {
+	var params lspTextDocumentParams
+	if len(msg.Params) > 0 {
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &rpcError{Code: rpcInternalError, Message: err.Error()}
+		}
+	}
+	uri := params.TextDocument.URI
+	var err error
+	switch msg.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				// Full document sync.
+				"textDocumentSync":   1,
+				"completionProvider": map[string]interface{}{},
+				"hoverProvider":      true,
+			},
+			"serverInfo": map[string]interface{}{"name": "kubectl-explore"},
+		}, nil
+	case "initialized", "$/cancelRequest", "$/setTrace", "textDocument/didSave":
+		return nil, nil
+	case "shutdown":
+		return nil, nil
+	case "textDocument/didOpen":
+		s.texts[uri] = params.TextDocument.Text
+		err = s.publishDiagnostics(uri)
+	case "textDocument/didChange":
+		if n := len(params.ContentChanges); n > 0 {
+			s.texts[uri] = params.ContentChanges[n-1].Text
+		}
+		err = s.publishDiagnostics(uri)
+	case "textDocument/didClose":
+		delete(s.texts, uri)
+		err = s.write(map[string]interface{}{
+			"jsonrpc": "2.0",
+			"method":  "textDocument/publishDiagnostics",
+			"params":  map[string]interface{}{"uri": uri, "diagnostics": []lspDiagnostic{}},
+		})
+	case "textDocument/completion":
+		var items []lspCompletionItem
+		items, err = s.completion(uri, params.Position)
+		if err == nil {
+			return items, nil
+		}
+	case "textDocument/hover":
+		var h *lspHover
+		h, err = s.hover(uri, params.Position)
+		if err == nil {
+			return h, nil
+		}
+	default:
+		return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("method not found: %s", msg.Method)}
+	}
+	if err != nil {
+		return nil, &rpcError{Code: rpcInternalError, Message: err.Error()}
+	}
+	return nil, nil
+}
+
+// read reads a message framed by the Content-Length header.
+func (s *languageServer) read() (*rpcMessage, error) {
+	header, err := textproto.NewReader(s.in).ReadMIMEHeader()
+	if err != nil {
+		if len(header) == 0 && (errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("read the header: %w", err)
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length: %w", err)
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(s.in, body); err != nil {
+		return nil, fmt.Errorf("read the body: %w", err)
+	}
+	var msg rpcMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("parse the message: %w", err)
+	}
+	return &msg, nil
+}
+
+func (s *languageServer) write(msg interface{}) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+// lspDocument is a YAML document in a text, which may contain several documents.
+type lspDocument struct {
+	// start is the line of the text where the document starts.
+	start int
+	lines []string
+}
+
+// splitDocuments splits the text at the --- separators.
+func splitDocuments(text string) []lspDocument {
+	lines := strings.Split(text, "\n")
+	var docs []lspDocument
+	start := 0
+	for i, line := range lines {
+		if strings.TrimRight(line, " \r") == "---" {
+			docs = append(docs, lspDocument{start: start, lines: lines[start:i]})
+			start = i + 1
+		}
+	}
+	return append(docs, lspDocument{start: start, lines: lines[start:]})
+}
+
+// documentAt returns the document containing the line of the text.
+func documentAt(text string, line int) (lspDocument, bool) {
+	for _, doc := range splitDocuments(text) {
+		if line >= doc.start && line < doc.start+len(doc.lines) {
+			return doc, true
+		}
+	}
+	return lspDocument{}, false
+}
+
+// gvk returns the group version kind from the top-level apiVersion and kind.
+func (d lspDocument) gvk() (schema.GroupVersionKind, bool) {
+	var apiVersion, kind string
+	for _, line := range d.lines {
+		if v, ok := strings.CutPrefix(line, "apiVersion:"); ok {
+			apiVersion = strings.Trim(strings.TrimSpace(v), `"'`)
+		}
+		if v, ok := strings.CutPrefix(line, "kind:"); ok {
+			kind = strings.Trim(strings.TrimSpace(v), `"'`)
+		}
+	}
+	if apiVersion == "" || kind == "" {
+		return schema.GroupVersionKind{}, false
+	}
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return schema.GroupVersionKind{}, false
+	}
+	return gv.WithKind(kind), true
+}
+
+// resource returns the schema of the kind, which is looked up once.
+func (s *languageServer) resource(gvk schema.GroupVersionKind) (*lspResource, error) {
+	if r, ok := s.resources[gvk]; ok {
+		return r, nil
+	}
+	mapping, err := s.o.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+	if err != nil {
+		return nil, fmt.Errorf("find the resource of %s: %w", gvk, err)
+	}
+	root, err := s.o.resourceSchema(mapping.Resource)
+	if err != nil {
+		return nil, err
+	}
+	visitor, err := s.o.visit(mapping.Resource)
+	if err != nil {
+		return nil, err
+	}
+	r := &lspResource{
+		gvr:   mapping.Resource,
+		root:  root,
+		paths: visitor.listPaths(func(path) bool { return true }),
+	}
+	s.resources[gvk] = r
+	return r, nil
+}
+
+// yamlLine is a line of YAML broken down into its indentation and key.
+type yamlLine struct {
+	// indent is the column of the key, after the dashes of list items.
+	indent int
+	// dash is the column of the last dash of a list item, or -1.
+	dash int
+	key  string
+	// inValue reports whether the line has a value after the key.
+	inValue bool
+}
+
+func parseYAMLLine(line string) (yamlLine, bool) {
+	l := yamlLine{dash: -1}
+	rest := strings.TrimLeft(line, " ")
+	l.indent = len(line) - len(rest)
+	if rest == "" || strings.HasPrefix(rest, "#") {
+		return l, false
+	}
+	for rest == "-" || strings.HasPrefix(rest, "- ") {
+		l.dash = l.indent
+		trimmed := strings.TrimLeft(strings.TrimPrefix(rest, "-"), " ")
+		l.indent += len(rest) - len(trimmed)
+		rest = trimmed
+	}
+	if i := strings.Index(rest, ":"); i > 0 && (i == len(rest)-1 || rest[i+1] == ' ') {
+		l.key = strings.Trim(rest[:i], `"'`)
+		l.inValue = strings.TrimSpace(rest[i+1:]) != ""
+	}
+	return l, true
+}
+
+// parentKeys returns the keys of the mappings enclosing a key at the line
+// of the document, found by indentation so that incomplete YAML works.
+func (d lspDocument) parentKeys(line int, current yamlLine) []string {
+	threshold := current.indent
+	if current.dash >= 0 {
+		// The key begins a list item, whose parent is at the column of the dash or less.
+		threshold = current.dash + 1
+	}
+	var keys []string
+	for i := line - 1; i >= 0 && threshold > 0; i-- {
+		l, ok := parseYAMLLine(d.lines[i])
+		if !ok || l.key == "" {
+			continue
+		}
+		switch {
+		case l.indent < threshold && !l.inValue:
+			keys = append([]string{l.key}, keys...)
+			threshold = l.indent
+			if l.dash >= 0 {
+				threshold = l.dash + 1
+			}
+		case l.indent == threshold && l.dash >= 0:
+			// The sibling key begins the list item.
+			threshold = l.dash + 1
+		}
+	}
+	return keys
+}
+
+// lookupKeys walks the schema along the keys. The keys of maps are arbitrary
+// and do not appear in the path, as in schemaVisitor.
+func lookupKeys(root proto.Schema, p path, keys []string) (proto.Schema, path, bool) {
+	s := root
+	for _, key := range keys {
+		s = elementSchema(s)
+		switch t := s.(type) {
+		case *proto.Map:
+			s = t.SubType
+		case *proto.Kind:
+			field, ok := t.Fields[key]
+			if !ok {
+				return nil, p, false
+			}
+			p = childPath(p, key, field)
+			s = field
+		default:
+			return nil, p, false
+		}
+	}
+	return s, p, true
+}
+
+// elementSchema follows references and the items of arrays.
+func elementSchema(s proto.Schema) proto.Schema {
+	for {
+		switch t := s.(type) {
+		case proto.Reference:
+			s = t.SubSchema()
+		case *proto.Array:
+			s = t.SubType
+		default:
+			return s
+		}
+	}
+}
+
+func resourcePath(gvr schema.GroupVersionResource) path {
+	resource := strings.ToLower(gvr.Resource)
+	return path{original: resource, withBrackets: resource}
+}
+
+// completion returns the fields of the mapping at the position.
+func (s *languageServer) completion(uri string, pos lspPosition) ([]lspCompletionItem, error) {
+	items := []lspCompletionItem{}
+	doc, ok := documentAt(s.texts[uri], pos.Line)
+	if !ok {
+		return items, nil
+	}
+	gvk, ok := doc.gvk()
+	if !ok {
+		return items, nil
+	}
+	r, err := s.resource(gvk)
+	if err != nil {
+		return nil, err
+	}
+	line := doc.lines[pos.Line-doc.start]
+	prefix := line[:byteOffset(line, pos.Character)]
+	current, ok := parseYAMLLine(prefix)
+	if !ok {
+		current = yamlLine{indent: len(prefix), dash: -1}
+	}
+	if current.inValue || strings.HasSuffix(prefix, ": ") {
+		return items, nil
+	}
+	field, _, ok := lookupKeys(r.root, resourcePath(r.gvr), doc.parentKeys(pos.Line-doc.start, current))
+	if !ok {
+		return items, nil
+	}
+	kind, ok := elementSchema(field).(*proto.Kind)
+	if !ok {
+		return items, nil
+	}
+	for _, key := range kind.Keys() {
+		items = append(items, lspCompletionItem{
+			Label:         key,
+			Kind:          lspCompletionItemKindProperty,
+			Detail:        "<" + explain.GetTypeName(kind.Fields[key]) + ">",
+			Documentation: schemaDescription(kind.Fields[key]),
+		})
+	}
+	return items, nil
+}
+
+// hover returns the explanation of the field at the line of the position.
+func (s *languageServer) hover(uri string, pos lspPosition) (*lspHover, error) {
+	doc, ok := documentAt(s.texts[uri], pos.Line)
+	if !ok {
+		return nil, nil
+	}
+	gvk, ok := doc.gvk()
+	if !ok {
+		return nil, nil
+	}
+	current, ok := parseYAMLLine(doc.lines[pos.Line-doc.start])
+	if !ok || current.key == "" {
+		return nil, nil
+	}
+	r, err := s.resource(gvk)
+	if err != nil {
+		return nil, err
+	}
+	keys := append(doc.parentKeys(pos.Line-doc.start, current), current.key)
+	_, p, ok := lookupKeys(r.root, resourcePath(r.gvr), keys)
+	if !ok {
+		return nil, nil
+	}
+	e := explainer{
+		gvr:                 r.gvr,
+		openAPIV3Client:     s.o.cachedOpenAPIV3Client,
+		documents:           s.documents,
+		enablePrintPath:     true,
+		enablePrintBrackets: s.o.showBrackets,
+	}
+	var w bytes.Buffer
+	if err := e.explain(&w, p); err != nil {
+		return nil, err
+	}
+	return &lspHover{Contents: lspMarkupContent{Kind: "plaintext", Value: w.String()}}, nil
+}
+
+func (s *languageServer) publishDiagnostics(uri string) error {
+	diagnostics := []lspDiagnostic{}
+	for _, doc := range splitDocuments(s.texts[uri]) {
+		diagnostics = append(diagnostics, s.diagnose(doc)...)
+	}
+	return s.write(map[string]interface{}{
+		"jsonrpc": "2.0",
+		"method":  "textDocument/publishDiagnostics",
+		"params":  map[string]interface{}{"uri": uri, "diagnostics": diagnostics},
+	})
+}
+
+// diagnose reports the unknown fields of the document. Documents that are
+// not valid YAML yet, or not Kubernetes objects, are skipped.
+func (s *languageServer) diagnose(doc lspDocument) []lspDiagnostic {
+	var root yaml.Node
+	if err := yaml.Unmarshal([]byte(strings.Join(doc.lines, "\n")), &root); err != nil || len(root.Content) == 0 {
+		return nil
+	}
+	gvk, ok := doc.gvk()
+	if !ok {
+		return nil
+	}
+	r, err := s.resource(gvk)
+	if err != nil {
+		var diagnostics []lspDiagnostic
+		for i, line := range doc.lines {
+			if strings.HasPrefix(line, "kind:") {
+				diagnostics = append(diagnostics, lspDiagnostic{
+					Range: lspRange{
+						Start: lspPosition{Line: doc.start + i},
+						End:   lspPosition{Line: doc.start + i, Character: utf16Len(line)},
+					},
+					Severity: lspSeverityWarning,
+					Source:   "kubectl-explore",
+					Message:  err.Error(),
+				})
+			}
+		}
+		return diagnostics
+	}
+	var diagnostics []lspDiagnostic
+	v := &manifestValidator{paths: r.paths}
+	var walk func(n *yaml.Node, s proto.Schema, p path)
+	walk = func(n *yaml.Node, s proto.Schema, p path) {
+		if r, ok := s.(proto.Reference); ok {
+			s = r.SubSchema()
+		}
+		switch t := s.(type) {
+		case *proto.Array:
+			if n.Kind == yaml.SequenceNode {
+				for _, item := range n.Content {
+					walk(item, t.SubType, p)
+				}
+			}
+		case *proto.Map:
+			if n.Kind == yaml.MappingNode {
+				for i := 1; i < len(n.Content); i += 2 {
+					walk(n.Content[i], t.SubType, p)
+				}
+			}
+		case *proto.Kind:
+			if n.Kind != yaml.MappingNode {
+				return
+			}
+			preserveUnknownFields, _ := t.GetExtensions()["x-kubernetes-preserve-unknown-fields"].(bool)
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				key, value := n.Content[i], n.Content[i+1]
+				field, ok := t.Fields[key.Value]
+				if ok {
+					walk(value, field, childPath(p, key.Value, field))
+					continue
+				}
+				if preserveUnknownFields {
+					continue
+				}
+				msg := fmt.Sprintf("unknown field %q", key.Value)
+				if suggestion := v.nearest(childPath(p, key.Value, nil)); !suggestion.isEmpty() {
+					msg += fmt.Sprintf(", did you mean %s?", suggestion.original)
+				}
+				line := doc.start + key.Line - 1
+				// The columns of the YAML parser are counted in runes.
+				start := utf16Len(string([]rune(doc.lines[key.Line-1])[:key.Column-1]))
+				diagnostics = append(diagnostics, lspDiagnostic{
+					Range: lspRange{
+						Start: lspPosition{Line: line, Character: start},
+						End:   lspPosition{Line: line, Character: start + utf16Len(key.Value)},
+					},
+					Severity: lspSeverityError,
+					Source:   "kubectl-explore",
+					Message:  msg,
+				})
+			}
+		}
+	}
+	walk(root.Content[0], r.root, resourcePath(r.gvr))
+	return diagnostics
+}
+
+// byteOffset converts the character of a position, counted in UTF-16 code
+// units, into the byte offset in the line. It is clamped to the line because
+// clients may send a position out of it.
+func byteOffset(line string, character int) int {
+	units := 0
+	for i, r := range line {
+		if units >= character {
+			return i
+		}
+		units += utf16.RuneLen(r)
+	}
+	return len(line)
+}
+
+// utf16Len returns the length of the string in UTF-16 code units.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
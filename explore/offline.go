@@ -0,0 +1,349 @@
+package explore
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	openapiclient "k8s.io/client-go/openapi"
+)
+
+// offlineSource serves the API resources, the REST mapping and the schemas
+// from OpenAPI v3 documents instead of a cluster.
+type offlineSource struct {
+	groupVersions map[string]openapiclient.GroupVersion
+	lists         []*metav1.APIResourceList
+	mapper        *meta.DefaultRESTMapper
+}
+
+var (
+	_ discoveryInterface   = (*offlineSource)(nil)
+	_ openapiclient.Client = (*offlineSource)(nil)
+)
+
+// loadOpenAPIDir loads OpenAPI v3 documents from the directory and synthesizes
+// the API resources from their paths.
+// Both the api/openapi-spec/v3 layout of kubernetes/kubernetes, e.g. apis__apps__v1_openapi.json,
+// and the layout of the /openapi/v3 endpoint, e.g. apis/apps/v1.json, are supported.
+func loadOpenAPIDir(dir string) (map[schema.GroupVersion][]byte, []*metav1.APIResourceList, error) {
+	documents := make(map[schema.GroupVersion][]byte)
+	err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(p) != ".json" {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		gv, ok := parseGroupVersionPath(openAPIDirKey(rel))
+		if !ok {
+			// e.g. version_openapi.json or apis__apps_openapi.json
+			return nil
+		}
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		documents[gv] = b
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("load OpenAPI v3 documents from %s: %w", dir, err)
+	}
+	if len(documents) == 0 {
+		return nil, nil, fmt.Errorf("no OpenAPI v3 documents found in %s", dir)
+	}
+	var lists []*metav1.APIResourceList
+	for gv, b := range documents {
+		var doc openAPIV3Document
+		if err := json.Unmarshal(b, &doc); err != nil {
+			return nil, nil, fmt.Errorf("parse OpenAPI v3 document for %s: %w", gv, err)
+		}
+		if list := doc.resourceList(gv); list != nil {
+			lists = append(lists, list)
+		}
+	}
+	return documents, lists, nil
+}
+
+// openAPIDirKey converts a file path relative to the OpenAPI directory into
+// the key of the group version in openapi.Client.Paths().
+func openAPIDirKey(rel string) string {
+	key := strings.TrimSuffix(filepath.ToSlash(rel), ".json")
+	key = strings.TrimSuffix(key, "_openapi")
+	return strings.ReplaceAll(key, "__", "/")
+}
+
+// parseGroupVersionPath is the inverse of groupVersionPath.
+func parseGroupVersionPath(p string) (schema.GroupVersion, bool) {
+	segments := strings.Split(p, "/")
+	switch {
+	case len(segments) == 2 && segments[0] == "api" && segments[1] != "":
+		return schema.GroupVersion{Version: segments[1]}, true
+	case len(segments) == 3 && segments[0] == "apis" && segments[1] != "" && segments[2] != "":
+		return schema.GroupVersion{Group: segments[1], Version: segments[2]}, true
+	default:
+		return schema.GroupVersion{}, false
+	}
+}
+
+// resourceList synthesizes the discovery data of the group version from the
+// paths of the document and their x-kubernetes-group-version-kind.
+func (d openAPIV3Document) resourceList(gv schema.GroupVersion) *metav1.APIResourceList {
+	prefix := "/" + groupVersionPath(gv) + "/"
+	paths, _ := d["paths"].(map[string]interface{})
+	resources := make(map[string]*metav1.APIResource)
+	for p, v := range paths {
+		rest, ok := strings.CutPrefix(p, prefix)
+		if !ok {
+			continue
+		}
+		segments := strings.Split(rest, "/")
+		namespaced := false
+		if len(segments) > 2 && segments[0] == "namespaces" && segments[1] == "{namespace}" {
+			segments = segments[2:]
+			namespaced = true
+		}
+		if segments[0] == "" || segments[0] == "watch" {
+			continue
+		}
+		// Skip subresources such as pods/{name}/status.
+		if len(segments) > 2 || (len(segments) == 2 && segments[1] != "{name}") {
+			continue
+		}
+		operations, _ := v.(map[string]interface{})
+		var gvk schema.GroupVersionKind
+		for _, method := range []string{"get", "post", "put", "patch", "delete"} {
+			operation, _ := operations[method].(map[string]interface{})
+			if got, ok := parseGroupVersionKind(operation["x-kubernetes-group-version-kind"]); ok {
+				gvk = got
+				break
+			}
+		}
+		if gvk.Kind == "" || gvk.GroupVersion() != gv {
+			continue
+		}
+		r, ok := resources[segments[0]]
+		if !ok {
+			r = &metav1.APIResource{
+				Name:         segments[0],
+				SingularName: strings.ToLower(gvk.Kind),
+				Kind:         gvk.Kind,
+			}
+			resources[segments[0]] = r
+		}
+		r.Namespaced = r.Namespaced || namespaced
+	}
+	if len(resources) == 0 {
+		return nil
+	}
+	list := &metav1.APIResourceList{GroupVersion: gv.String()}
+	for _, r := range resources {
+		list.APIResources = append(list.APIResources, *r)
+	}
+	sort.SliceStable(list.APIResources, func(i, j int) bool {
+		return list.APIResources[i].Name < list.APIResources[j].Name
+	})
+	return list
+}
+
+func newOfflineSource(documents map[schema.GroupVersion][]byte, lists []*metav1.APIResourceList) (*offlineSource, error) {
+	s := &offlineSource{
+		groupVersions: make(map[string]openapiclient.GroupVersion),
+		lists:         lists,
+	}
+	var gvs []schema.GroupVersion
+	for gv, b := range documents {
+		gvs = append(gvs, gv)
+		gvPath := groupVersionPath(gv)
+		s.groupVersions[gvPath] = &staticGroupVersion{
+			path:     gvPath,
+			document: b,
+		}
+	}
+	s.mapper = meta.NewDefaultRESTMapper(gvs)
+	for _, list := range lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range list.APIResources {
+			scope := meta.RESTScopeRoot
+			if r.Namespaced {
+				scope = meta.RESTScopeNamespace
+			}
+			s.mapper.AddSpecific(
+				gv.WithKind(r.Kind),
+				gv.WithResource(r.Name),
+				gv.WithResource(r.SingularName),
+				scope,
+			)
+		}
+	}
+	sort.SliceStable(s.lists, func(i, j int) bool {
+		return s.lists[i].GroupVersion < s.lists[j].GroupVersion
+	})
+	return s, nil
+}
+
+// mergeDocuments adds the documents of src to dst. The schemas and the paths
+// of a group version documented in both are merged, e.g. for a
+// CustomResourceDefinition of a group in the OpenAPI directory, and one
+// defined differently in both is an error.
+func mergeDocuments(dst, src map[schema.GroupVersion][]byte) error {
+	for gv, b := range src {
+		existing, ok := dst[gv]
+		if !ok {
+			dst[gv] = b
+			continue
+		}
+		var doc, other openAPIV3Document
+		if err := json.Unmarshal(existing, &doc); err != nil {
+			return fmt.Errorf("parse OpenAPI v3 document for %s: %w", gv, err)
+		}
+		if err := json.Unmarshal(b, &other); err != nil {
+			return fmt.Errorf("parse OpenAPI v3 document for %s: %w", gv, err)
+		}
+		components := openAPIV3Object(doc, "components")
+		if err := mergeOpenAPIV3Objects(openAPIV3Object(components, "schemas"), other.schemas(), "schema", gv); err != nil {
+			return err
+		}
+		paths, _ := other["paths"].(map[string]interface{})
+		if err := mergeOpenAPIV3Objects(openAPIV3Object(doc, "paths"), paths, "path", gv); err != nil {
+			return err
+		}
+		merged, err := json.Marshal(doc)
+		if err != nil {
+			return err
+		}
+		dst[gv] = merged
+	}
+	return nil
+}
+
+// openAPIV3Object returns the object at the key of the parent, which is
+// added if missing.
+func openAPIV3Object(parent map[string]interface{}, key string) map[string]interface{} {
+	object, _ := parent[key].(map[string]interface{})
+	if object == nil {
+		object = make(map[string]interface{})
+		parent[key] = object
+	}
+	return object
+}
+
+// mergeOpenAPIV3Objects adds the values of src to dst, failing on a key whose
+// value differs in both, e.g. a schema of the same name.
+func mergeOpenAPIV3Objects(dst, src map[string]interface{}, kind string, gv schema.GroupVersion) error {
+	for key, v := range src {
+		if current, ok := dst[key]; ok && !reflect.DeepEqual(current, v) {
+			return fmt.Errorf("conflicting %s %s in the OpenAPI v3 documents for %s", kind, key, gv)
+		}
+		dst[key] = v
+	}
+	return nil
+}
+
+// mergeResourceLists appends the lists of src to dst, adding the resources of
+// a group version listed in both to the list in dst.
+func mergeResourceLists(dst, src []*metav1.APIResourceList) []*metav1.APIResourceList {
+	for _, list := range src {
+		i := slices.IndexFunc(dst, func(l *metav1.APIResourceList) bool {
+			return l.GroupVersion == list.GroupVersion
+		})
+		if i < 0 {
+			dst = append(dst, list)
+			continue
+		}
+		merged := dst[i].DeepCopy()
+		merged.APIResources = append(merged.APIResources, list.APIResources...)
+		dst[i] = merged
+	}
+	return dst
+}
+
+func (s *offlineSource) ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error) {
+	for _, list := range s.lists {
+		if list.GroupVersion == groupVersion {
+			return list, nil
+		}
+	}
+	return nil, fmt.Errorf("no resources found for API version %q", groupVersion)
+}
+
+// ServerPreferredResources returns each resource in the highest version of
+// its group serving it. A resource missing from the highest version is kept in
+// an older one, as the discovery of an API server does.
+func (s *offlineSource) ServerPreferredResources() ([]*metav1.APIResourceList, error) {
+	gvs := make(map[*metav1.APIResourceList]schema.GroupVersion, len(s.lists))
+	for _, list := range s.lists {
+		gv, err := schema.ParseGroupVersion(list.GroupVersion)
+		if err != nil {
+			return nil, err
+		}
+		gvs[list] = gv
+	}
+	sorted := slices.Clone(s.lists)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := gvs[sorted[i]], gvs[sorted[j]]
+		if a.Group != b.Group {
+			return a.Group < b.Group
+		}
+		return version.CompareKubeAwareVersionStrings(a.Version, b.Version) > 0
+	})
+	seen := make(map[schema.GroupResource]bool)
+	var lists []*metav1.APIResourceList
+	for _, list := range sorted {
+		var resources []metav1.APIResource
+		for _, r := range list.APIResources {
+			gr := gvs[list].WithResource(r.Name).GroupResource()
+			if seen[gr] {
+				continue
+			}
+			seen[gr] = true
+			resources = append(resources, r)
+		}
+		if len(resources) > 0 {
+			lists = append(lists, &metav1.APIResourceList{
+				TypeMeta:     list.TypeMeta,
+				GroupVersion: list.GroupVersion,
+				APIResources: resources,
+			})
+		}
+	}
+	return lists, nil
+}
+
+func (s *offlineSource) Paths() (map[string]openapiclient.GroupVersion, error) {
+	return s.groupVersions, nil
+}
+
+type staticGroupVersion struct {
+	path     string
+	document []byte
+}
+
+func (g *staticGroupVersion) Schema(contentType string) ([]byte, error) {
+	if contentType != runtime.ContentTypeJSON {
+		return nil, fmt.Errorf("unsupported content type: %s", contentType)
+	}
+	return g.document, nil
+}
+
+func (g *staticGroupVersion) ServerRelativeURL() string {
+	return "/openapi/v3/" + g.path
+}
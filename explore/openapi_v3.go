@@ -0,0 +1,516 @@
+package explore
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	openapi_v3 "github.com/google/gnostic-models/openapiv3"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	openapiclient "k8s.io/client-go/openapi"
+	"k8s.io/kube-openapi/pkg/util/proto"
+	"k8s.io/kubectl/pkg/util/openapi"
+)
+
+// maxResolveDepth bounds how many $ref/allOf hops are followed for one schema
+// so that a malformed or recursive document cannot loop forever.
+const maxResolveDepth = 32
+
+// openAPIV3Documents decodes the OpenAPI v3 documents served by the client
+// at most once per group version.
+type openAPIV3Documents struct {
+	client openapiclient.Client
+	mu     sync.Mutex
+	docs   map[schema.GroupVersion]openAPIV3Document
+}
+
+func newOpenAPIV3Documents(c openapiclient.Client) *openAPIV3Documents {
+	return &openAPIV3Documents{
+		client: c,
+		docs:   make(map[schema.GroupVersion]openAPIV3Document),
+	}
+}
+
+func (d *openAPIV3Documents) get(gv schema.GroupVersion) (openAPIV3Document, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if doc, ok := d.docs[gv]; ok {
+		return doc, nil
+	}
+	paths, err := d.client.Paths()
+	if err != nil {
+		return nil, fmt.Errorf("fetch the list of group versions: %w", err)
+	}
+	gvPath := groupVersionPath(gv)
+	c, ok := paths[gvPath]
+	if !ok {
+		return nil, fmt.Errorf("couldn't find OpenAPI v3 document for %q", gv)
+	}
+	b, err := c.Schema(runtime.ContentTypeJSON)
+	if err != nil {
+		return nil, fmt.Errorf("fetch OpenAPI v3 document for %s: %w", gvPath, err)
+	}
+	var doc openAPIV3Document
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, fmt.Errorf("parse OpenAPI v3 document for %s: %w", gvPath, err)
+	}
+	d.docs[gv] = doc
+	return doc, nil
+}
+
+// groupVersionPath returns the key of the group version in openapi.Client.Paths().
+func groupVersionPath(gv schema.GroupVersion) string {
+	if gv.Group == "" {
+		return "api/" + gv.Version
+	}
+	return "apis/" + gv.Group + "/" + gv.Version
+}
+
+// openAPIV3Document is a decoded OpenAPI v3 document of a group version.
+type openAPIV3Document map[string]interface{}
+
+// kindFor finds the kind of the resource from the operations of its paths,
+// the same way as kubectl explain does.
+func (d openAPIV3Document) kindFor(gvr schema.GroupVersionResource) (schema.GroupVersionKind, error) {
+	prefix := "/api/" + gvr.Version
+	if gvr.Group != "" {
+		prefix = "/apis/" + gvr.Group + "/" + gvr.Version
+	}
+	paths, _ := d["paths"].(map[string]interface{})
+	for _, p := range []string{
+		prefix + "/" + gvr.Resource,
+		prefix + "/" + gvr.Resource + "/{name}",
+		prefix + "/namespaces/{namespace}/" + gvr.Resource,
+		prefix + "/namespaces/{namespace}/" + gvr.Resource + "/{name}",
+	} {
+		operations, _ := paths[p].(map[string]interface{})
+		for _, method := range []string{"get", "post", "put", "patch", "delete"} {
+			operation, _ := operations[method].(map[string]interface{})
+			if gvk, ok := parseGroupVersionKind(operation["x-kubernetes-group-version-kind"]); ok {
+				return gvk, nil
+			}
+		}
+	}
+	return schema.GroupVersionKind{}, fmt.Errorf("GVR (%s) not found in OpenAPI schema", gvr)
+}
+
+func parseGroupVersionKind(v interface{}) (schema.GroupVersionKind, bool) {
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return schema.GroupVersionKind{}, false
+	}
+	group, _ := m["group"].(string)
+	version, _ := m["version"].(string)
+	kind, _ := m["kind"].(string)
+	if kind == "" {
+		return schema.GroupVersionKind{}, false
+	}
+	return schema.GroupVersionKind{Group: group, Version: version, Kind: kind}, true
+}
+
+// schemas returns the definitions in components.schemas.
+func (d openAPIV3Document) schemas() map[string]interface{} {
+	components, _ := d["components"].(map[string]interface{})
+	schemas, _ := components["schemas"].(map[string]interface{})
+	return schemas
+}
+
+// lookupKind returns the definition tagged with the given kind.
+func (d openAPIV3Document) lookupKind(gvk schema.GroupVersionKind) (map[string]interface{}, error) {
+	for _, v := range d.schemas() {
+		s, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		gvks, _ := s["x-kubernetes-group-version-kind"].([]interface{})
+		for _, g := range gvks {
+			if got, ok := parseGroupVersionKind(g); ok && got == gvk {
+				return s, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("GVK %s not found in OpenAPI schema", gvk)
+}
+
+func (d openAPIV3Document) ref(ref string) map[string]interface{} {
+	name := strings.TrimPrefix(ref, "#/components/schemas/")
+	s, _ := d.schemas()[name].(map[string]interface{})
+	return s
+}
+
+// resolve follows $ref and single-element allOf, which is how the API server
+// attaches a description or a default to a field referring to a definition.
+func (d openAPIV3Document) resolve(s map[string]interface{}) map[string]interface{} {
+	for i := 0; s != nil && i < maxResolveDepth; i++ {
+		if ref, ok := s["$ref"].(string); ok {
+			s = d.ref(ref)
+			continue
+		}
+		if allOf, ok := s["allOf"].([]interface{}); ok && len(allOf) == 1 && s["properties"] == nil {
+			s, _ = allOf[0].(map[string]interface{})
+			continue
+		}
+		return s
+	}
+	return s
+}
+
+// objectSchema resolves s to the schema holding properties,
+// descending into array items and map values.
+func (d openAPIV3Document) objectSchema(s map[string]interface{}) map[string]interface{} {
+	for i := 0; s != nil && i < maxResolveDepth; i++ {
+		s = d.resolve(s)
+		if s == nil {
+			return nil
+		}
+		if _, ok := s["properties"]; ok {
+			return s
+		}
+		if items, ok := s["items"].(map[string]interface{}); ok {
+			s = items
+			continue
+		}
+		if additionalProperties, ok := s["additionalProperties"].(map[string]interface{}); ok {
+			s = additionalProperties
+			continue
+		}
+		return s
+	}
+	return nil
+}
+
+// itemSchema returns the schema of the items of the array, or of the values
+// of the map, if s is one.
+func (d openAPIV3Document) itemSchema(s map[string]interface{}) map[string]interface{} {
+	resolved := d.resolve(s)
+	if items, ok := resolved["items"].(map[string]interface{}); ok {
+		return items
+	}
+	if additionalProperties, ok := resolved["additionalProperties"].(map[string]interface{}); ok {
+		return additionalProperties
+	}
+	return nil
+}
+
+// lookupField follows fields from s and returns the schema of the last field
+// and the object schema declaring it.
+func (d openAPIV3Document) lookupField(s map[string]interface{}, fields []string) (field, parent map[string]interface{}, err error) {
+	field = s
+	for _, name := range fields {
+		parent = d.objectSchema(field)
+		properties, _ := parent["properties"].(map[string]interface{})
+		field, _ = properties[name].(map[string]interface{})
+		if field == nil {
+			return nil, nil, fmt.Errorf("field %q does not exist", name)
+		}
+	}
+	return field, parent, nil
+}
+
+// typeName guesses a short type name such as string, []Container or
+// map[string]string in the same manner as kubectl explain.
+func (d openAPIV3Document) typeName(s map[string]interface{}) string {
+	if items, ok := s["items"].(map[string]interface{}); ok {
+		return "[]" + d.typeName(items)
+	}
+	if additionalProperties, ok := s["additionalProperties"].(map[string]interface{}); ok {
+		return "map[string]" + d.typeName(additionalProperties)
+	}
+	if allOf, ok := s["allOf"].([]interface{}); ok && len(allOf) == 1 && s["properties"] == nil {
+		if sub, ok := allOf[0].(map[string]interface{}); ok {
+			return d.typeName(sub)
+		}
+	}
+	if ref, ok := s["$ref"].(string); ok {
+		refSchema := d.ref(ref)
+		if t, _ := refSchema["type"].(string); t != "" && t != "object" {
+			return t
+		}
+		name := ref[strings.LastIndex(ref, "/")+1:]
+		if name = name[strings.LastIndex(name, ".")+1:]; name != "" {
+			return name
+		}
+		return "Object"
+	}
+	if t, _ := s["type"].(string); t != "" && t != "object" {
+		return t
+	}
+	return "Object"
+}
+
+// description returns the description of the field, falling back to the
+// description of the definition it refers to.
+func (d openAPIV3Document) description(s map[string]interface{}) string {
+	if desc, _ := s["description"].(string); desc != "" {
+		return desc
+	}
+	desc, _ := d.resolve(s)["description"].(string)
+	return desc
+}
+
+// enum returns the enum of the field, falling back to that of the definition it refers to.
+func (d openAPIV3Document) enum(s map[string]interface{}) []interface{} {
+	if enum, ok := s["enum"].([]interface{}); ok {
+		return enum
+	}
+	enum, _ := d.resolve(s)["enum"].([]interface{})
+	return enum
+}
+
+// keywords returns the keywords of the field in names, falling back to those
+// of the definition it refers to.
+func (d openAPIV3Document) keywords(s map[string]interface{}, names []string) map[string]interface{} {
+	keywords := make(map[string]interface{})
+	resolved := d.resolve(s)
+	for _, name := range names {
+		if v, ok := s[name]; ok {
+			keywords[name] = v
+		} else if v, ok := resolved[name]; ok {
+			keywords[name] = v
+		}
+	}
+	return keywords
+}
+
+// validationRule is a CEL rule in x-kubernetes-validations.
+type validationRule struct {
+	Rule              string `json:"rule"`
+	Message           string `json:"message,omitempty"`
+	MessageExpression string `json:"messageExpression,omitempty"`
+}
+
+// validationRules returns the x-kubernetes-validations of the field,
+// falling back to those of the definition it refers to.
+func (d openAPIV3Document) validationRules(s map[string]interface{}) []validationRule {
+	validations, ok := s["x-kubernetes-validations"].([]interface{})
+	if !ok {
+		validations, _ = d.resolve(s)["x-kubernetes-validations"].([]interface{})
+	}
+	var rules []validationRule
+	for _, v := range validations {
+		m, _ := v.(map[string]interface{})
+		rule, _ := m["rule"].(string)
+		if rule == "" {
+			continue
+		}
+		message, _ := m["message"].(string)
+		messageExpression, _ := m["messageExpression"].(string)
+		rules = append(rules, validationRule{Rule: rule, Message: message, MessageExpression: messageExpression})
+	}
+	return rules
+}
+
+// required reports whether the object schema requires the field.
+func required(object map[string]interface{}, name string) bool {
+	fields, _ := object["required"].([]interface{})
+	for _, f := range fields {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// propertyNames returns the sorted property names of the object schema.
+func propertyNames(object map[string]interface{}) []string {
+	properties, _ := object["properties"].(map[string]interface{})
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// openAPIV3Resources looks up the schema of a resource from the OpenAPI v3
+// document of its group version, so that only the documents of the explored
+// resources are fetched.
+type openAPIV3Resources struct {
+	client openapiclient.Client
+	mu     sync.Mutex
+	models map[schema.GroupVersion]map[schema.GroupVersionKind]proto.Schema
+	errs   map[schema.GroupVersion]error
+}
+
+var _ openapi.Resources = (*openAPIV3Resources)(nil)
+
+func newOpenAPIV3Resources(c openapiclient.Client) *openAPIV3Resources {
+	return &openAPIV3Resources{
+		client: c,
+		models: make(map[schema.GroupVersion]map[schema.GroupVersionKind]proto.Schema),
+		errs:   make(map[schema.GroupVersion]error),
+	}
+}
+
+// lookupResource returns the schema of the kind, or nil if the document of
+// its group version does not define it.
+func (r *openAPIV3Resources) lookupResource(gvk schema.GroupVersionKind) (proto.Schema, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	gv := gvk.GroupVersion()
+	if err, ok := r.errs[gv]; ok {
+		return nil, err
+	}
+	if models, ok := r.models[gv]; ok {
+		return models[gvk], nil
+	}
+	models, err := r.load(gv)
+	if err != nil {
+		err = fmt.Errorf("load the schema of %s: %w", gv, err)
+		r.errs[gv] = err
+		return nil, err
+	}
+	r.models[gv] = models
+	return models[gvk], nil
+}
+
+func (r *openAPIV3Resources) LookupResource(gvk schema.GroupVersionKind) proto.Schema {
+	s, _ := r.lookupResource(gvk)
+	return s
+}
+
+func (r *openAPIV3Resources) GetConsumes(schema.GroupVersionKind, string) []string {
+	return nil
+}
+
+// load parses the definitions of the document into the schema model walked
+// by schemaVisitor and indexes them by x-kubernetes-group-version-kind.
+func (r *openAPIV3Resources) load(gv schema.GroupVersion) (map[schema.GroupVersionKind]proto.Schema, error) {
+	doc, models, err := r.loadModels(groupVersionPath(gv))
+	if err != nil {
+		return nil, err
+	}
+	resources := make(map[schema.GroupVersionKind]proto.Schema)
+	for name, v := range doc.schemas() {
+		definition, _ := v.(map[string]interface{})
+		gvks, _ := definition["x-kubernetes-group-version-kind"].([]interface{})
+		for _, g := range gvks {
+			if gvk, ok := parseGroupVersionKind(g); ok {
+				if model := models.LookupModel(name); model != nil {
+					resources[gvk] = model
+				}
+			}
+		}
+	}
+	return resources, nil
+}
+
+// definition is an OpenAPI definition and the document declaring it.
+type definition struct {
+	schema proto.Schema
+	doc    openAPIV3Document
+}
+
+// definitions returns the definitions of all the documents by their names,
+// e.g. io.k8s.api.core.v1.ObjectReference. A definition repeated in several
+// documents is taken from the first one.
+func (r *openAPIV3Resources) definitions() (map[string]definition, error) {
+	paths, err := r.client.Paths()
+	if err != nil {
+		return nil, err
+	}
+	gvPaths := make([]string, 0, len(paths))
+	for p := range paths {
+		// Skip the documents of non-resource endpoints such as /version.
+		if strings.HasPrefix(p, "api/") || strings.HasPrefix(p, "apis/") {
+			gvPaths = append(gvPaths, p)
+		}
+	}
+	sort.Strings(gvPaths)
+	definitions := make(map[string]definition)
+	for _, p := range gvPaths {
+		doc, models, err := r.loadModels(p)
+		if err != nil {
+			return nil, fmt.Errorf("load the schema of %s: %w", p, err)
+		}
+		for _, name := range models.ListModels() {
+			if _, ok := definitions[name]; !ok {
+				definitions[name] = definition{schema: models.LookupModel(name), doc: doc}
+			}
+		}
+	}
+	return definitions, nil
+}
+
+// loadModels parses the document at the path of openapi.Client.Paths().
+func (r *openAPIV3Resources) loadModels(gvPath string) (openAPIV3Document, proto.Models, error) {
+	paths, err := r.client.Paths()
+	if err != nil {
+		return nil, nil, err
+	}
+	c, ok := paths[gvPath]
+	if !ok {
+		return nil, nil, fmt.Errorf("couldn't find OpenAPI v3 document")
+	}
+	b, err := c.Schema(runtime.ContentTypeJSON)
+	if err != nil {
+		return nil, nil, err
+	}
+	var doc openAPIV3Document
+	if err := json.Unmarshal(b, &doc); err != nil {
+		return nil, nil, err
+	}
+	normalized, err := json.Marshal(normalizeForProto(map[string]interface{}(doc)))
+	if err != nil {
+		return nil, nil, err
+	}
+	v3, err := openapi_v3.ParseDocument(normalized)
+	if err != nil {
+		return nil, nil, err
+	}
+	models, err := proto.NewOpenAPIV3Data(v3)
+	if err != nil {
+		return nil, nil, err
+	}
+	return doc, models, nil
+}
+
+// enumExtension keeps the enum of a schema in the schema model walked by schemaVisitor.
+const enumExtension = "x-kubectl-explore-enum"
+
+// normalizeForProto rewrites the document in place so that the proto package
+// can walk it like a swagger 2.0 document:
+//   - {"allOf": [{"$ref": ...}], "description": ...} becomes {"$ref": ..., "description": ...}
+//     because a schema without a type is treated as an arbitrary value.
+//   - An object with x-kubernetes-group-version-kind gets empty properties
+//     because a top-level kind without properties is rejected.
+//   - An enum is copied to enumExtension because the proto package keeps
+//     only the extensions of a schema.
+func normalizeForProto(v interface{}) interface{} {
+	switch t := v.(type) {
+	case map[string]interface{}:
+		for k, child := range t {
+			t[k] = normalizeForProto(child)
+		}
+		if enum, ok := t["enum"]; ok {
+			t[enumExtension] = enum
+		}
+		if _, ok := t["x-kubernetes-group-version-kind"]; ok && t["type"] == "object" && t["properties"] == nil {
+			t["properties"] = map[string]interface{}{}
+		}
+		allOf, ok := t["allOf"].([]interface{})
+		if !ok || len(allOf) != 1 || t["type"] != nil || t["properties"] != nil {
+			return t
+		}
+		sub, _ := allOf[0].(map[string]interface{})
+		ref, ok := sub["$ref"].(string)
+		if !ok {
+			return t
+		}
+		inlined := map[string]interface{}{"$ref": ref}
+		if desc, ok := t["description"]; ok {
+			inlined["description"] = desc
+		}
+		return inlined
+	case []interface{}:
+		for i, child := range t {
+			t[i] = normalizeForProto(child)
+		}
+		return t
+	default:
+		return v
+	}
+}
@@ -2,9 +2,12 @@ package explore
 
 import (
 	"bytes"
+	"encoding/json"
 	"fmt"
+	"maps"
 	"os"
 	"regexp"
+	"slices"
 	"sort"
 	"strings"
 
@@ -12,6 +15,7 @@ import (
 	"github.com/spf13/cobra"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/discovery"
@@ -19,28 +23,53 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/kube-openapi/pkg/util/proto"
 	cmdutil "k8s.io/kubectl/pkg/cmd/util"
-	"k8s.io/kubectl/pkg/util/openapi"
 )
 
 type Options struct {
 	// User input
-	apiVersion       string
-	inputFieldPath   string
-	disablePrintPath bool
-	showBrackets     bool
+	apiVersion         string
+	inputFieldPath     string
+	disablePrintPath   bool
+	showBrackets       bool
+	output             string
+	openAPIDir         string
+	filenames          []string
+	refreshCache       bool
+	cacheDir           string
+	searchDescriptions bool
+	showDetails        bool
+	filter             fieldFilter
+	multi              bool
+	setOnly            bool
+	tree               bool
+	drillDown          bool
 
 	// After completion
 	inputFieldPathRegex *regexp.Regexp
 	gvrs                []schema.GroupVersionResource
+	// manifests are the manifests passed with -f other than CustomResourceDefinitions.
+	manifests [][]byte
+	// crds are the CustomResourceDefinitions passed with -f.
+	crds []*customResourceDefinition
+	// objects are the live object or the manifests of each resource.
+	objects map[schema.GroupVersionResource][]map[string]interface{}
 
 	// Dependencies
 	genericclioptions.IOStreams
-	discovery             discovery.CachedDiscoveryInterface
+	discovery             discoveryInterface
 	mapper                meta.RESTMapper
-	schema                openapi.Resources
+	schema                *openAPIV3Resources
 	cachedOpenAPIV3Client openapiclient.Client
 }
 
+// discoveryInterface is the subset of the discovery client used to list API resources.
+type discoveryInterface interface {
+	ServerResourcesForGroupVersion(groupVersion string) (*metav1.APIResourceList, error)
+	ServerPreferredResources() ([]*metav1.APIResourceList, error)
+}
+
+var _ discoveryInterface = (discovery.CachedDiscoveryInterface)(nil)
+
 func NewCmd() *cobra.Command {
 	o := NewOptions(genericclioptions.IOStreams{
 		In:     os.Stdin,
@@ -49,7 +78,7 @@ func NewCmd() *cobra.Command {
 	})
 
 	cmd := &cobra.Command{
-		Use:   "kubectl explore [resource|regex] [flags]",
+		Use:   "explore [resource|regex] [flags]",
 		Short: "Fuzzy-find the field to explain from all API resources.",
 		Example: `
 # Fuzzy-find the field to explain from all API resources.
@@ -69,18 +98,126 @@ kubectl explore --context=onecontext
 
 # Fuzzy-find the field to explain from a specific api-version
 kubectl explore --api-version=apps/v1
+
+# Print the explanation of the selected field as JSON or YAML.
+kubectl explore pod.*node -o json
+kubectl explore pod.*node --output=yaml
+
+# Print a minimal manifest containing the selected field.
+kubectl explore pod.*capabilities -o skeleton
+
+# Print a command querying the selected field of live objects.
+kubectl explore pod.*image -o jsonpath
+kubectl explore pod.*image -o custom-columns
+kubectl explore pod.*image -o jq
+
+# Select multiple fields with Tab and print a manifest template containing all of them.
+kubectl explore deployments --multi
+
+# Navigate the fields as a tree, expanding and collapsing them with Enter.
+kubectl explore deployments --tree
+
+# Keep the finder open, drilling down into the selected object and going back up with "..".
+kubectl explore deployments --drill-down
+
+# Fuzzy-find the field to explain from OpenAPI v3 documents on disk without a cluster.
+kubectl explore --openapi-dir=./kubernetes/api/openapi-spec/v3 deployments
+
+# Fuzzy-find the field to explain from CustomResourceDefinition manifests that are not installed yet.
+kubectl explore -f ./config/crd/bases/
+
+# Fuzzy-find the field to explain by what it does.
+kubectl explore --search-descriptions "terminate gracefully"
+
+# Show the type, the default and the enum of each field next to its path.
+kubectl explore pods --show-details
+
+# Fuzzy-find only from the fields with the attributes, e.g. the required fields of a CRD.
+kubectl explore crontabs --required-only
+kubectl explore pods --type=boolean
+kubectl explore pods --has-enum
+kubectl explore pods --deprecated
+kubectl explore pods --readonly
+
+# Fuzzy-find the field to explain with the values of a live object, optionally filtered by a regex.
+kubectl explore pod/my-pod -n my-namespace
+kubectl explore pod/my-pod -n my-namespace image
+
+# Fuzzy-find only from the fields set on a live object or on local manifests.
+kubectl explore pod/my-pod -n my-namespace --set-only
+kubectl explore -f ./deployment.yaml --set-only
+
+# Show the fields added, removed and changed between two API versions.
+kubectl explore diff hpa --from autoscaling/v1 --to autoscaling/v2
+
+# Show the fields that exist only in one of two clusters.
+kubectl explore compare deployments --context a --context b
+
+# Report the unknown fields, type mismatches and missing required fields of manifests.
+kubectl explore validate -f ./deployment.yaml
+
+# Run a Language Server Protocol server for Kubernetes manifests over stdio.
+kubectl explore lsp
+
+# Browse the fields of all API resources in a web browser at http://localhost:8080.
+kubectl explore serve --port 8080
+
+# Write a Markdown page with the fields of each API resource and an index of them.
+kubectl explore docs --out ./site
+
+# List the resources and the fields where an OpenAPI definition is embedded.
+kubectl explore uses io.k8s.api.core.v1.PodSpec
+
+# Fuzzy-find an OpenAPI definition, e.g. one not served as a resource, and then its field.
+kubectl explore definitions LabelSelector
+
+# Report the deprecated API versions and fields, with the versions replacing them.
+kubectl explore deprecations
+
+# Put the resource or the regex after --, and the flags before it, if it is the name of a subcommand.
+kubectl explore -- uses
 `,
+		// Arguments not matching a subcommand are a resource or a regex.
+		// A subcommand name after "--" is not looked up as a subcommand.
+		Args: cobra.ArbitraryArgs,
+		Annotations: map[string]string{
+			cobra.CommandDisplayNameAnnotation: "kubectl explore",
+		},
 	}
 	cmd.Flags().StringVar(&o.apiVersion, "api-version", o.apiVersion, "Get different explanations for particular API version (API group/version)")
 	cmd.Flags().BoolVar(&o.disablePrintPath, "disable-print-path", o.disablePrintPath, "Disable printing the path to explain")
-	cmd.Flags().BoolVar(&o.showBrackets, "show-brackets", o.showBrackets, "Enable showing brackets for fields that are arrays")
-	kubeConfigFlags := defaultConfigFlags().WithWarningPrinter(o.IOStreams)
+	cmd.Flags().StringVarP(&o.output, "output", "o", o.output, fmt.Sprintf("Output format of the explanation. One of: %s", strings.Join(outputFormats, "|")))
+	cmd.Flags().StringSliceVarP(&o.filenames, "filename", "f", o.filenames, "Explore CustomResourceDefinitions in the files or directories instead of a cluster, or the fields of the other manifests in them")
+	cmd.Flags().BoolVar(&o.setOnly, "set-only", o.setOnly, "Restrict the fields to those set on the resource/name object or the manifests passed with -f, and the resources to those of the manifests")
+	cmd.Flags().BoolVar(&o.tree, "tree", o.tree, "Navigate the fields as a tree, expanding and collapsing them with Enter")
+	cmd.Flags().BoolVar(&o.drillDown, "drill-down", o.drillDown, "Re-open the finder with the children of the selected object, going back up with .. or Esc")
+	cmd.Flags().BoolVar(&o.multi, "multi", o.multi, "Select multiple fields of a resource with Tab and print a manifest template containing all of them")
+	cmd.Flags().BoolVar(&o.searchDescriptions, "search-descriptions", o.searchDescriptions, "Match the regex and the fuzzy finder against the descriptions of fields as well as their paths")
+	cmd.Flags().BoolVar(&o.filter.requiredOnly, "required-only", o.filter.requiredOnly, "Restrict the fields to the required ones")
+	cmd.Flags().StringVar(&o.filter.fieldType, "type", o.filter.fieldType, "Restrict the fields to those of the type shown by kubectl explain, e.g. boolean, []string or Object")
+	cmd.Flags().BoolVar(&o.filter.hasEnum, "has-enum", o.filter.hasEnum, "Restrict the fields to those with an enum")
+	cmd.Flags().BoolVar(&o.filter.deprecated, "deprecated", o.filter.deprecated, "Restrict the fields to those described as deprecated")
+	cmd.Flags().BoolVar(&o.filter.readOnly, "readonly", o.filter.readOnly, "Restrict the fields to those described as read-only or populated by the system")
+	cmd.Flags().BoolVar(&o.showDetails, "show-details", o.showDetails, "Show the type, the default and the enum of each field next to its path in the fuzzy finder")
 	flags := cmd.PersistentFlags()
+	flags.BoolVar(&o.showBrackets, "show-brackets", o.showBrackets, "Enable showing brackets for fields that are arrays")
+	flags.StringVar(&o.openAPIDir, "openapi-dir", o.openAPIDir, "Explore OpenAPI v3 documents in the directory instead of a cluster, e.g. api/openapi-spec/v3 of kubernetes/kubernetes")
+	flags.BoolVar(&o.refreshCache, "refresh-cache", o.refreshCache, "Discard the cached OpenAPI v3 documents and fetch them again")
+	kubeConfigFlags := defaultConfigFlags().WithWarningPrinter(o.IOStreams)
 	kubeConfigFlags.AddFlags(flags)
 	matchVersionKubeConfigFlags := cmdutil.NewMatchVersionFlags(kubeConfigFlags)
 	matchVersionKubeConfigFlags.AddFlags(flags)
 	f := cmdutil.NewFactory(matchVersionKubeConfigFlags)
 
+	cmd.AddCommand(newDiffCmd(o, f))
+	cmd.AddCommand(newCompareCmd(o, kubeConfigFlags))
+	cmd.AddCommand(newValidateCmd(o, f))
+	cmd.AddCommand(newLSPCmd(o, f))
+	cmd.AddCommand(newServeCmd(o, f))
+	cmd.AddCommand(newDocsCmd(o, f))
+	cmd.AddCommand(newUsesCmd(o, f))
+	cmd.AddCommand(newDefinitionsCmd(o, f))
+	cmd.AddCommand(newDeprecationsCmd(o, f))
 	cmd.Run = func(_ *cobra.Command, args []string) {
 		cmdutil.CheckErr(o.Complete(f, args))
 		cmdutil.CheckErr(o.Run())
@@ -96,11 +233,28 @@ func defaultConfigFlags() *genericclioptions.ConfigFlags {
 func NewOptions(streams genericclioptions.IOStreams) *Options {
 	return &Options{
 		IOStreams: streams,
+		output:    outputPlaintext,
 	}
 }
 
 func (o *Options) Complete(f cmdutil.Factory, args []string) error {
 	var err error
+	if !slices.Contains(outputFormats, o.output) {
+		return fmt.Errorf("unsupported output format %q, must be one of: %s", o.output, strings.Join(outputFormats, "|"))
+	}
+	if o.multi && o.output != outputPlaintext && o.output != outputSkeleton {
+		return fmt.Errorf("--multi prints a manifest template and cannot be used with --output=%s", o.output)
+	}
+	if o.multi && o.tree {
+		return fmt.Errorf("--multi and --tree cannot be used together")
+	}
+	if o.drillDown && (o.multi || o.tree) {
+		return fmt.Errorf("--drill-down cannot be used with --multi or --tree")
+	}
+	// The tree shows the names of the fields only.
+	if o.tree && (o.showDetails || o.searchDescriptions) {
+		return fmt.Errorf("--tree cannot be used with --show-details or --search-descriptions")
+	}
 	if len(args) == 0 {
 		o.inputFieldPathRegex = regexp.MustCompile(".*")
 	} else {
@@ -110,28 +264,26 @@ func (o *Options) Complete(f cmdutil.Factory, args []string) error {
 		}
 		o.inputFieldPath = args[0]
 	}
-	o.discovery, err = f.ToDiscoveryClient()
-	if err != nil {
-		return err
-	}
-	o.mapper, err = f.ToRESTMapper()
-	if err != nil {
+	if err := o.completeDependencies(f); err != nil {
 		return err
 	}
-	o.schema, err = f.OpenAPISchema()
-	if err != nil {
-		return err
+	if len(o.filenames) > 0 && len(o.crds) == 0 && !o.setOnly {
+		return fmt.Errorf("no CustomResourceDefinition found in %s", strings.Join(o.filenames, ", "))
 	}
-	if c, err := f.OpenAPIV3Client(); err == nil {
-		o.cachedOpenAPIV3Client, err = newCachedOpenAPIClient(c)
-		if err != nil {
+	// The other manifests, e.g. a kustomization.yaml next to CustomResourceDefinitions,
+	// are only explored with --set-only.
+	if o.setOnly {
+		if err := o.completeManifests(); err != nil {
 			return err
 		}
-	} else {
-		return err
 	}
 
 	if o.inputFieldPath == "" {
+		if len(o.objects) > 0 {
+			// --set-only explores the resources of the manifests.
+			o.gvrs = o.objectGVRs()
+			return nil
+		}
 		g, err := o.findGVR()
 		if err != nil {
 			return err
@@ -145,6 +297,23 @@ func (o *Options) Complete(f cmdutil.Factory, args []string) error {
 		return err
 	}
 
+	// The query of --search-descriptions is prose, e.g. "node selector term",
+	// whose first word is not a resource to narrow the search down to.
+	if o.searchDescriptions {
+		o.gvrs = gvrs
+		if len(o.objects) > 0 {
+			o.gvrs = o.objectGVRs()
+		}
+		return nil
+	}
+
+	// resource/name explores the fields of the object.
+	if resource, name, ok := strings.Cut(o.inputFieldPath, "/"); ok && name != "" {
+		if gvar, ok := gvarMap[resource]; ok {
+			return o.completeObject(f, gvar, name, args[1:])
+		}
+	}
+
 	if gvar, ok := gvarMap[o.inputFieldPath]; ok {
 		o.inputFieldPathRegex = regexp.MustCompile(".*")
 		o.gvrs = []schema.GroupVersionResource{gvar.GroupVersionResource}
@@ -165,6 +334,9 @@ func (o *Options) Complete(f cmdutil.Factory, args []string) error {
 	// inputFiledPath is treated as a regex.
 	if gvar == nil {
 		o.gvrs = gvrs
+		if len(o.objects) > 0 {
+			o.gvrs = o.objectGVRs()
+		}
 		return nil
 	}
 	// Overwrite the regex if the inputFieldPath contains a valid resource name.
@@ -198,70 +370,375 @@ func (o *Options) Complete(f cmdutil.Factory, args []string) error {
 	return nil
 }
 
+// completeManifests maps the manifests to their resources.
+// The manifests of kinds not served, e.g. a Kustomization, are skipped with a warning.
+func (o *Options) completeManifests() error {
+	for _, b := range o.manifests {
+		var manifest map[string]interface{}
+		if err := json.Unmarshal(b, &manifest); err != nil {
+			return err
+		}
+		u := unstructured.Unstructured{Object: manifest}
+		gvk := u.GroupVersionKind()
+		if gvk.Kind == "" {
+			return fmt.Errorf("manifest %q has no kind", u.GetName())
+		}
+		mapping, err := o.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if meta.IsNoMatchError(err) {
+			fmt.Fprintf(o.ErrOut, "Warning: skip the manifest of %s: no resource found\n", gvk)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("find the resource of %s: %w", gvk, err)
+		}
+		if o.objects == nil {
+			o.objects = make(map[schema.GroupVersionResource][]map[string]interface{})
+		}
+		o.objects[mapping.Resource] = append(o.objects[mapping.Resource], manifest)
+	}
+	return nil
+}
+
+func (o *Options) objectGVRs() []schema.GroupVersionResource {
+	return slices.SortedFunc(maps.Keys(o.objects), func(a, b schema.GroupVersionResource) int {
+		return strings.Compare(a.String(), b.String())
+	})
+}
+
+func (o *Options) completeObject(f cmdutil.Factory, gvar *groupVersionAPIResource, name string, args []string) error {
+	if _, ok := o.discovery.(*offlineSource); ok {
+		return fmt.Errorf("cannot get %s/%s without a cluster", gvar.Resource, name)
+	}
+	o.inputFieldPathRegex = regexp.MustCompile(".*")
+	if len(args) > 0 {
+		var err error
+		o.inputFieldPathRegex, err = regexp.Compile(args[0])
+		if err != nil {
+			return err
+		}
+	}
+	o.gvrs = []schema.GroupVersionResource{gvar.GroupVersionResource}
+	object, err := getObject(f, gvar, name)
+	if err != nil {
+		return err
+	}
+	o.objects = map[schema.GroupVersionResource][]map[string]interface{}{
+		gvar.GroupVersionResource: {object},
+	}
+	return nil
+}
+
+// completeDependencies sets up the clients from the cluster, or from the
+// OpenAPI directory and the CustomResourceDefinition files if they are given.
+// The other manifests in the files are kept to explore their fields.
+func (o *Options) completeDependencies(f cmdutil.Factory) error {
+	var crds []*customResourceDefinition
+	if len(o.filenames) > 0 {
+		var err error
+		crds, o.manifests, err = loadManifests(o.filenames)
+		if err != nil {
+			return err
+		}
+		o.crds = crds
+	}
+	if o.openAPIDir != "" || len(crds) > 0 {
+		documents := make(map[schema.GroupVersion][]byte)
+		var lists []*metav1.APIResourceList
+		if o.openAPIDir != "" {
+			d, l, err := loadOpenAPIDir(o.openAPIDir)
+			if err != nil {
+				return err
+			}
+			if err := mergeDocuments(documents, d); err != nil {
+				return err
+			}
+			lists = mergeResourceLists(lists, l)
+		}
+		if len(crds) > 0 {
+			d, l, err := crdDocuments(crds)
+			if err != nil {
+				return err
+			}
+			// The CustomResourceDefinitions may add resources to a group
+			// version in the OpenAPI directory.
+			if err := mergeDocuments(documents, d); err != nil {
+				return err
+			}
+			lists = mergeResourceLists(lists, l)
+		}
+		src, err := newOfflineSource(documents, lists)
+		if err != nil {
+			return err
+		}
+		o.discovery = src
+		o.mapper = src.mapper
+		o.schema = newOpenAPIV3Resources(src)
+		o.cachedOpenAPIV3Client = src
+		return nil
+	}
+	var err error
+	o.discovery, err = f.ToDiscoveryClient()
+	if err != nil {
+		return err
+	}
+	o.mapper, err = f.ToRESTMapper()
+	if err != nil {
+		return err
+	}
+	if o.cacheDir == "" {
+		if config, err := f.ToRESTConfig(); err == nil {
+			o.cacheDir = defaultCacheDir(config.Host)
+		}
+	}
+	if o.refreshCache && o.cacheDir != "" {
+		if err := os.RemoveAll(o.cacheDir); err != nil {
+			return fmt.Errorf("refresh the cache: %w", err)
+		}
+	}
+	if c, err := f.OpenAPIV3Client(); err == nil {
+		o.cachedOpenAPIV3Client, err = newCachedOpenAPIClient(c, o.cacheDir)
+		if err != nil {
+			return err
+		}
+	} else {
+		return err
+	}
+	o.schema = newOpenAPIV3Resources(o.cachedOpenAPIV3Client)
+	return nil
+}
+
 func (o *Options) Run() error {
+	if o.setOnly && len(o.objects) == 0 {
+		return fmt.Errorf("--set-only requires a resource/name or manifests passed with -f")
+	}
 	pathExplainers := make(map[path]explainer)
+	documents := newOpenAPIV3Documents(o.cachedOpenAPIV3Client)
+	descriptions := make(map[path]string)
+	// Descriptions are prose, so they are matched case-insensitively.
+	descriptionRegex, err := regexp.Compile("(?i)" + o.inputFieldPathRegex.String())
+	if err != nil {
+		return err
+	}
 	var paths []path
+	visitors := make(map[schema.GroupVersionResource]*schemaVisitor)
 	for _, gvr := range o.gvrs {
-		visitor := &schemaVisitor{
-			pathSchema: make(map[path]proto.Schema),
-			prevPath: path{
-				original:     strings.ToLower(gvr.Resource),
-				withBrackets: strings.ToLower(gvr.Resource),
-			},
-			err: nil,
-		}
-		gvk, err := o.mapper.KindFor(gvr)
+		visitor, err := o.visit(gvr)
 		if err != nil {
-			return fmt.Errorf("get the group version kind: %w", err)
-		}
-		s := o.schema.LookupResource(gvk)
-		if s == nil {
-			return fmt.Errorf("no schema found for %s", gvk)
-		}
-		s.Accept(visitor)
-		if visitor.err != nil {
-			return visitor.err
+			return err
 		}
+		visitors[gvr] = visitor
+		objects := o.objects[gvr]
+		setOnly := o.setOnlyFilter(gvr, visitor)
 		filteredPaths := visitor.listPaths(func(s path) bool {
-			return o.inputFieldPathRegex.MatchString(s.original)
+			if !setOnly(s) {
+				return false
+			}
+			if o.inputFieldPathRegex.MatchString(s.original) {
+				return true
+			}
+			return o.searchDescriptions && descriptionRegex.MatchString(visitor.descriptions[s])
 		})
 		for _, p := range filteredPaths {
-			pathExplainers[p] = explainer{
+			descriptions[p] = visitor.descriptions[p]
+		}
+		for _, p := range filteredPaths {
+			e := explainer{
 				gvr:                 gvr,
 				openAPIV3Client:     o.cachedOpenAPIV3Client,
+				documents:           documents,
 				enablePrintPath:     !o.disablePrintPath,
 				enablePrintBrackets: o.showBrackets,
+				outputFormat:        o.output,
+				mapPaths:            visitor.mapPaths,
+			}
+			if len(objects) > 0 {
+				e.object = objects[0]
 			}
+			pathExplainers[p] = e
 			paths = append(paths, p)
 		}
 	}
 	if len(paths) == 0 {
 		return fmt.Errorf("no paths found for %q", o.inputFieldPath)
 	}
-	if len(paths) == 1 {
-		return pathExplainers[paths[0]].explain(o.Out, paths[0])
+	if len(paths) == 1 && !o.drillDown {
+		if o.multi {
+			return o.printTemplate(pathExplainers, paths)
+		}
+		return pathExplainers[paths[0]].print(o.Out, paths[0])
 	}
 	sort.SliceStable(paths, func(i, j int) bool {
 		return paths[i].original < paths[j].original
 	})
-	idx, err := fuzzyfinder.Find(
-		paths,
-		func(i int) string { return paths[i].original },
-		fuzzyfinder.WithPreviewWindow(func(i, _, _ int) string {
-			if i < 0 {
-				return ""
-			}
-			var w bytes.Buffer
-			if err := pathExplainers[paths[i]].explain(&w, paths[i]); err != nil {
-				return fmt.Sprintf("preview is broken: %s", err)
+	if o.tree {
+		return o.runTree(pathExplainers, paths)
+	}
+	// The summaries are computed up front because the label of each path is
+	// built every time the fuzzy finder is drawn.
+	var summaries map[path]string
+	if o.showDetails {
+		summaries = fieldSummaries(pathExplainers, paths, visitors)
+	}
+	label := func(i int) string {
+		l := paths[i].original
+		if summary := summaries[paths[i]]; summary != "" {
+			l += " " + summary
+		}
+		if o.searchDescriptions {
+			l += "    " + descriptionSnippet(descriptions[paths[i]], descriptionRegex)
+		}
+		if o.objects == nil {
+			return l
+		}
+		gvr := pathExplainers[paths[i]].gvr
+		if populated(o.objects[gvr], paths[i], visitors[gvr].mapPaths) {
+			return populatedMarker + l
+		}
+		return strings.Repeat(" ", len([]rune(populatedMarker))) + l
+	}
+	preview := fuzzyfinder.WithPreviewWindow(func(i, _, _ int) string {
+		if i < 0 {
+			return ""
+		}
+		var w bytes.Buffer
+		if err := pathExplainers[paths[i]].explain(&w, paths[i]); err != nil {
+			return fmt.Sprintf("preview is broken: %s", err)
+		}
+		return w.String()
+	})
+	if o.drillDown {
+		return newDrillDown(o, visitors).run(pathExplainers, paths, label, preview)
+	}
+	if o.multi {
+		idxs, err := fuzzyfinder.FindMulti(paths, label, preview)
+		if err != nil {
+			return err
+		}
+		selected := make([]path, len(idxs))
+		for i, idx := range idxs {
+			selected[i] = paths[idx]
+		}
+		return o.printTemplate(pathExplainers, selected)
+	}
+	idx, err := fuzzyfinder.Find(paths, label, preview)
+	if err != nil {
+		return err
+	}
+	return pathExplainers[paths[idx]].print(o.Out, paths[idx])
+}
+
+// setOnlyFilter returns the filter keeping the paths set on the objects of the
+// resource with --set-only, or all the paths otherwise.
+func (o *Options) setOnlyFilter(gvr schema.GroupVersionResource, visitor *schemaVisitor) func(path) bool {
+	if !o.setOnly {
+		return func(path) bool { return true }
+	}
+	objects := o.objects[gvr]
+	return func(p path) bool {
+		return populated(objects, p, visitor.mapPaths)
+	}
+}
+
+// fieldSummaries returns the summaries of the paths shown with --show-details.
+// The definition of the kind is looked up once per resource, and the paths of
+// a resource without it are summarized by their types alone.
+func fieldSummaries(pathExplainers map[path]explainer, paths []path, visitors map[schema.GroupVersionResource]*schemaVisitor) map[path]string {
+	type kindSchema struct {
+		doc  openAPIV3Document
+		root map[string]interface{}
+	}
+	kinds := make(map[schema.GroupVersionResource]kindSchema)
+	summaries := make(map[path]string, len(paths))
+	for _, p := range paths {
+		e := pathExplainers[p]
+		k, ok := kinds[e.gvr]
+		if !ok {
+			if doc, _, root, err := e.rootSchema(); err == nil {
+				k = kindSchema{doc: doc, root: root}
 			}
-			return w.String()
+			kinds[e.gvr] = k
+		}
+		summaries[p] = fieldSummary(k.doc, k.root, p, visitors[e.gvr].pathSchema[p])
+	}
+	return summaries
+}
+
+// printTemplate prints a manifest template containing all the paths,
+// which must belong to the same resource.
+func (o *Options) printTemplate(pathExplainers map[path]explainer, paths []path) error {
+	e := pathExplainers[paths[0]]
+	for _, p := range paths[1:] {
+		if gvr := pathExplainers[p].gvr; gvr != e.gvr {
+			return fmt.Errorf("--multi requires the fields of one resource, but both %s and %s are selected", e.gvr, gvr)
+		}
+	}
+	return e.template(o.Out, paths)
+}
+
+// visit walks the schema of the resource and collects its paths.
+func (o *Options) visit(gvr schema.GroupVersionResource) (*schemaVisitor, error) {
+	visitor := &schemaVisitor{
+		pathSchema: make(map[path]proto.Schema),
+		references: make(map[string][]path),
+		required:   make(map[path]bool),
+		mapPaths:   make(map[string]bool),
+		filter:     o.filter,
+		prevPath: path{
+			original:     strings.ToLower(gvr.Resource),
+			withBrackets: strings.ToLower(gvr.Resource),
 		},
-		))
+		err: nil,
+	}
+	if o.searchDescriptions {
+		visitor.descriptions = make(map[path]string)
+	}
+	s, err := o.resourceSchema(gvr)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	s.Accept(visitor)
+	if visitor.err != nil {
+		return nil, visitor.err
+	}
+	return visitor, nil
+}
+
+// resourceSchema returns the schema of the kind of the resource.
+func (o *Options) resourceSchema(gvr schema.GroupVersionResource) (proto.Schema, error) {
+	gvk, err := o.mapper.KindFor(gvr)
+	if err != nil {
+		return nil, fmt.Errorf("get the group version kind: %w", err)
+	}
+	s, err := o.schema.lookupResource(gvk)
+	if err != nil {
+		return nil, err
+	}
+	if s == nil {
+		return nil, fmt.Errorf("no schema found for %s", gvk)
 	}
-	return pathExplainers[paths[idx]].explain(o.Out, paths[idx])
+	return s, nil
+}
+
+// snippetRadius is the number of characters shown around the match in a description.
+const snippetRadius = 40
+
+// descriptionSnippet cuts out the part of the description around the match of
+// the regex and marks the match as «match», so that the match is visible in the
+// fuzzy finder. The whole description is returned if every part of it matches.
+func descriptionSnippet(desc string, re *regexp.Regexp) string {
+	loc := re.FindStringIndex(desc)
+	if loc == nil || loc[0] == loc[1] || (loc[0] == 0 && loc[1] == len(desc)) {
+		return desc
+	}
+	start, end := loc[0], loc[1]
+	before, after := desc[:start], desc[end:]
+	if r := []rune(before); len(r) > snippetRadius {
+		before = "…" + string(r[len(r)-snippetRadius:])
+	}
+	if r := []rune(after); len(r) > snippetRadius {
+		after = string(r[:snippetRadius]) + "…"
+	}
+	return before + "«" + desc[start:end] + "»" + after
 }
 
 func (o *Options) apiResourceLists() ([]*metav1.APIResourceList, error) {
@@ -373,6 +850,29 @@ func (o *Options) findGVR() (schema.GroupVersionResource, error) {
 	return gvrs[idx], nil
 }
 
+// resourceFor finds the resource in the API version by its name, singular name, kind or short name.
+func (o *Options) resourceFor(apiVersion, name string) (schema.GroupVersionResource, error) {
+	gv, err := schema.ParseGroupVersion(apiVersion)
+	if err != nil {
+		return schema.GroupVersionResource{}, err
+	}
+	list, err := o.discovery.ServerResourcesForGroupVersion(apiVersion)
+	if err != nil {
+		return schema.GroupVersionResource{}, fmt.Errorf("failed to fetch resources for API version %q: %w", apiVersion, err)
+	}
+	for _, l := range filterOutSubresources(list) {
+		for _, resource := range l.APIResources {
+			if resource.Name == name ||
+				resource.SingularName == name ||
+				strings.EqualFold(resource.Kind, name) ||
+				slices.Contains(resource.ShortNames, name) {
+				return gv.WithResource(resource.Name), nil
+			}
+		}
+	}
+	return schema.GroupVersionResource{}, fmt.Errorf("no resource %q found for API version %q", name, apiVersion)
+}
+
 type groupVersionAPIResource struct {
 	schema.GroupVersionResource
 	metav1.APIResource
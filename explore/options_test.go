@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"os"
 	"path/filepath"
@@ -13,16 +14,20 @@ import (
 	"sync"
 	"testing"
 
-	openapi_v2 "github.com/google/gnostic/openapiv2"
 	"github.com/keisku/kubectl-explore/explore"
 	"github.com/stretchr/testify/require"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/cli-runtime/pkg/genericclioptions"
 	"k8s.io/client-go/discovery"
+	fakedynamic "k8s.io/client-go/dynamic/fake"
 	openapiclient "k8s.io/client-go/openapi"
 	"k8s.io/client-go/rest"
 	clienttestutil "k8s.io/client-go/util/testing"
 	cmdtesting "k8s.io/kubectl/pkg/cmd/testing"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/scheme"
 	"k8s.io/kubectl/pkg/util/openapi"
 )
 
@@ -31,32 +36,10 @@ const (
 	rawGithubusercontent       = "https://raw.githubusercontent.com"
 	openAPISpecV3DirFormat     = "/kubernetes/kubernetes/release-%s/api/openapi-spec/v3/"
 	openAPISpecV3FileURLFormat = rawGithubusercontent + openAPISpecV3DirFormat + "%s"
-	swaggerURLFormat           = "https://raw.githubusercontent.com/kubernetes/kubernetes/release-%s/api/openapi-spec/swagger.json"
 )
 
 var k8sVersions = []string{"1.27", "1.28", "1.29", "1.30", "1.31", "1.32", "1.33", "1.34"}
 
-func openAPIResources(version string) (openapi.Resources, error) {
-	resp, err := http.DefaultClient.Get(fmt.Sprintf(swaggerURLFormat, version))
-	if err != nil {
-		return nil, fmt.Errorf("fetch swagger.json: %s", err)
-	}
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("read response body: %s", err)
-	}
-	doc, err := openapi_v2.ParseDocument(body)
-	if err != nil {
-		return nil, fmt.Errorf("parse swagger.json: %s", err)
-	}
-	r, err := openapi.NewOpenAPIData(doc)
-	if err != nil {
-		return nil, fmt.Errorf("creates a new resource from the doc: %s", err)
-	}
-	return r, nil
-}
-
 func openAPISpecV3FilePaths(version string) ([]string, error) {
 	req, err := http.NewRequest("GET", fmt.Sprintf(openAPISpecV3PathURLFormat, version), nil)
 	if err != nil {
@@ -142,6 +125,14 @@ func makeOpenAPISpecV3Directory(version string) (string, error) {
 	return testdataDir, nil
 }
 
+func TestMain(m *testing.M) {
+	code := m.Run()
+	for _, testdata := range openAPISpecV3Directories {
+		os.RemoveAll(testdata)
+	}
+	os.Exit(code)
+}
+
 func convertFilename(filename string) string {
 	parts := strings.Split(filename, "__")
 	newPath := strings.Join(parts, "/")
@@ -179,7 +170,6 @@ func Test_Run(t *testing.T) {
 		}
 		fakeServers[version] = fakeServer
 		t.Cleanup(func() {
-			os.RemoveAll(testdata)
 			fakeServer.HttpServer.Close()
 		})
 	}
@@ -251,6 +241,7 @@ func Test_Run(t *testing.T) {
 		inputFieldPath   string
 		disablePrintPath bool
 		showBrackets     bool
+		output           string
 		expectRunError   bool
 		expectKeywords   []string
 		unexpectKeywords []string
@@ -399,6 +390,39 @@ func Test_Run(t *testing.T) {
 				"PATH: nodes.status.conditions.type",
 			},
 		},
+		{
+			inputFieldPath:   "nodes.status.conditions.type",
+			disablePrintPath: false,
+			showBrackets:     false,
+			output:           "json",
+			expectRunError:   false,
+			expectKeywords: []string{
+				`"name": "type"`,
+				`"path": "nodes.status.conditions.type"`,
+				`"pathWithBrackets": "nodes.status.conditions[].type"`,
+				`"kind": "Node"`,
+				`"type": "string"`,
+				`"required": true`,
+			},
+			unexpectKeywords: []string{
+				"PATH: nodes.status.conditions.type",
+			},
+		},
+		{
+			inputFieldPath:   "nodes.spec.taints$",
+			disablePrintPath: false,
+			showBrackets:     false,
+			output:           "yaml",
+			expectRunError:   false,
+			expectKeywords: []string{
+				"name: taints",
+				"path: nodes.spec.taints",
+				"pathWithBrackets: nodes.spec.taints[]",
+				"type: '[]Taint'",
+				"- description:",
+				"name: effect",
+			},
+		},
 	}
 	for _, tt := range tests {
 		for _, version := range k8sVersions {
@@ -420,9 +444,6 @@ func Test_Run(t *testing.T) {
 				tf.OpenAPIV3ClientFunc = func() (openapiclient.Client, error) {
 					return fakeDiscoveryClient.OpenAPIV3(), nil
 				}
-				tf.OpenAPISchemaFunc = func() (openapi.Resources, error) {
-					return openAPIResources(version)
-				}
 				tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
 
 				var stdin bytes.Buffer
@@ -436,6 +457,10 @@ func Test_Run(t *testing.T) {
 				explore.SetDisablePrintPath(opts, tt.disablePrintPath)
 				explore.SetShowBrackets(opts, tt.showBrackets)
 				explore.SetAPIVersion(opts, tt.apiVersion)
+				explore.SetCacheDir(opts, t.TempDir())
+				if tt.output != "" {
+					explore.SetOutput(opts, tt.output)
+				}
 				require.NoError(t, opts.Complete(tf, []string{tt.inputFieldPath}))
 				err := opts.Run()
 				if tt.expectRunError {
@@ -453,3 +478,1821 @@ func Test_Run(t *testing.T) {
 		}
 	}
 }
+
+func Test_Run_OpenAPIDir(t *testing.T) {
+	tests := []struct {
+		apiVersion         string
+		inputFieldPath     string
+		searchDescriptions bool
+		output             string
+		multi              bool
+		tree               bool
+		drillDown          bool
+		requiredOnly       bool
+		fieldType          string
+		hasEnum            bool
+		deprecated         bool
+		readOnly           bool
+		expectKeywords     []string
+	}{
+		{
+			inputFieldPath: "nodes.*providerID",
+			expectKeywords: []string{
+				"Node",
+				"providerID",
+				"PATH: nodes.spec.providerID",
+			},
+		},
+		{
+			inputFieldPath: "deployments.*maxSurge",
+			expectKeywords: []string{
+				"apps",
+				"Deployment",
+				"PATH: deployments.spec.strategy.rollingUpdate.maxSurge",
+			},
+		},
+		{
+			apiVersion:     "autoscaling/v1",
+			inputFieldPath: "horizontalpodautoscalers.*targetCPU",
+			expectKeywords: []string{
+				"autoscaling",
+				"HorizontalPodAutoscaler",
+				"v1",
+				"PATH: horizontalpodautoscalers.spec.targetCPUUtilizationPercentage",
+			},
+		},
+		{
+			// The query is searched in all the resources even if it starts with
+			// the name of one, e.g. pod.
+			inputFieldPath:     "Pod IP range assigned to the node",
+			searchDescriptions: true,
+			expectKeywords: []string{
+				"PATH: nodes.spec.podCIDR\n",
+			},
+		},
+		{
+			inputFieldPath: "pods.spec.containers.securityContext.capabilities.add",
+			output:         "skeleton",
+			expectKeywords: []string{
+				`apiVersion: v1
+kind: Pod
+metadata:
+  name: ""
+spec:
+  containers:
+    - securityContext:
+        capabilities:
+          add:
+            - ""
+`,
+			},
+		},
+		{
+			// IntOrString has no type, but is not an object.
+			inputFieldPath: "deployments.spec.strategy.rollingUpdate.maxSurge",
+			output:         "skeleton",
+			expectKeywords: []string{
+				"      maxSurge: 0\n",
+			},
+		},
+		{
+			inputFieldPath: "deployments.spec.replicas$",
+			multi:          true,
+			expectKeywords: []string{
+				"kind: Deployment",
+				"  replicas: 0 # <integer> Number of desired pods.",
+			},
+		},
+		{
+			inputFieldPath: "pods.spec.containers.image$",
+			output:         "jsonpath",
+			expectKeywords: []string{
+				"kubectl get pods -o jsonpath='{.items[*].spec.containers[*].image}'\n",
+			},
+		},
+		{
+			inputFieldPath: "pods.spec.containers.image$",
+			output:         "custom-columns",
+			expectKeywords: []string{
+				"kubectl get pods -o custom-columns='NAME:.metadata.name,IMAGE:.spec.containers[*].image'\n",
+			},
+		},
+		{
+			inputFieldPath: "deployments.spec.template.spec.containers.image$",
+			output:         "jq",
+			expectKeywords: []string{
+				"kubectl get deployments.v1.apps -o json | jq '.items[] | .spec.template.spec.containers[].image'\n",
+			},
+		},
+		{
+			inputFieldPath: "pods.spec.containers.ports$",
+			expectKeywords: []string{
+				"EXTENSIONS:\n",
+				"  x-kubernetes-list-type\tmap\n",
+				"  x-kubernetes-list-map-keys\t[containerPort, protocol]\n",
+				"  x-kubernetes-patch-strategy\tmerge\n",
+				"  x-kubernetes-patch-merge-key\tcontainerPort\n",
+			},
+		},
+		{
+			inputFieldPath: "pods.spec.containers.ports.",
+			requiredOnly:   true,
+			expectKeywords: []string{"PATH: pods.spec.containers.ports.containerPort"},
+		},
+		{
+			inputFieldPath: "pods.spec.containers.(tty|name)$",
+			fieldType:      "boolean",
+			expectKeywords: []string{"PATH: pods.spec.containers.tty"},
+		},
+		{
+			inputFieldPath: "pods.spec.containers.ports.",
+			hasEnum:        true,
+			expectKeywords: []string{"PATH: pods.spec.containers.ports.protocol"},
+		},
+		{
+			inputFieldPath: "pods.spec.serviceAccount",
+			deprecated:     true,
+			expectKeywords: []string{"PATH: pods.spec.serviceAccount\n"},
+		},
+		{
+			inputFieldPath: "^pods.metadata.(uid|name)$",
+			readOnly:       true,
+			expectKeywords: []string{"PATH: pods.metadata.uid"},
+		},
+		{
+			// A single field is explained without the tree.
+			inputFieldPath: "deployments.spec.replicas$",
+			tree:           true,
+			expectKeywords: []string{
+				"PATH: deployments.spec.replicas",
+				"Number of desired pods.",
+			},
+		},
+		{
+			// A single field without children is explained without drilling down.
+			inputFieldPath: "deployments.spec.replicas$",
+			drillDown:      true,
+			expectKeywords: []string{
+				"PATH: deployments.spec.replicas",
+				"Number of desired pods.",
+			},
+		},
+	}
+	for _, tt := range tests {
+		for _, version := range k8sVersions {
+			t.Run(fmt.Sprintf("version: %s inputFieldPath: %s", version, tt.inputFieldPath), func(t *testing.T) {
+				var stdout bytes.Buffer
+				opts := explore.NewOptions(genericclioptions.IOStreams{
+					In:     &bytes.Buffer{},
+					Out:    &stdout,
+					ErrOut: &bytes.Buffer{},
+				})
+				explore.SetOpenAPIDir(opts, openAPISpecV3Directories[version])
+				explore.SetAPIVersion(opts, tt.apiVersion)
+				explore.SetSearchDescriptions(opts, tt.searchDescriptions)
+				explore.SetMulti(opts, tt.multi)
+				explore.SetTree(opts, tt.tree)
+				explore.SetDrillDown(opts, tt.drillDown)
+				explore.SetRequiredOnly(opts, tt.requiredOnly)
+				explore.SetFieldType(opts, tt.fieldType)
+				explore.SetHasEnum(opts, tt.hasEnum)
+				explore.SetDeprecated(opts, tt.deprecated)
+				explore.SetReadOnly(opts, tt.readOnly)
+				if tt.output != "" {
+					explore.SetOutput(opts, tt.output)
+				}
+				// The factory must not be used in offline mode.
+				require.NoError(t, opts.Complete(nil, []string{tt.inputFieldPath}))
+				require.NoError(t, opts.Run())
+				for _, keyword := range tt.expectKeywords {
+					require.Contains(t, stdout.String(), keyword)
+				}
+			})
+		}
+	}
+}
+
+const crontabCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.stable.example.com
+spec:
+  group: stable.example.com
+  scope: Namespaced
+  names:
+    plural: crontabs
+    singular: crontab
+    kind: CronTab
+    shortNames:
+    - ct
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        description: CronTab runs a command periodically.
+        type: object
+        properties:
+          apiVersion:
+            type: string
+          kind:
+            type: string
+          metadata:
+            type: object
+          spec:
+            description: Desired state of the CronTab.
+            type: object
+            required:
+            - cronSpec
+            x-kubernetes-validations:
+            - rule: "!has(self.image) || self.image != ''"
+              message: image must not be empty
+            properties:
+              cronSpec:
+                description: Schedule in Cron format.
+                type: string
+                maxLength: 64
+              image:
+                description: Container image to run.
+                type: string
+              tags:
+                description: Tags of the CronTab.
+                type: array
+                maxItems: 5
+                items:
+                  type: string
+                  maxLength: 3
+                  x-kubernetes-validations:
+                  - rule: self == self.lowerAscii()
+                    message: tags must be lowercase
+              port:
+                description: Port to expose, by number or name.
+                x-kubernetes-int-or-string: true
+                anyOf:
+                - type: integer
+                - type: string
+              days:
+                description: Days of the week to run on.
+                type: array
+                items:
+                  type: string
+                  enum:
+                  - Mon
+                  - Tue
+                  - Wed
+                  - Thu
+                  - Fri
+              containers:
+                type: array
+                x-kubernetes-list-type: map
+                x-kubernetes-list-map-keys:
+                - name
+                items:
+                  type: object
+                  properties:
+                    name:
+                      description: Name of the container.
+                      type: string
+              sidecars:
+                description: Sidecar containers by name.
+                type: object
+                additionalProperties:
+                  type: object
+                  properties:
+                    image:
+                      description: Container image of the sidecar.
+                      type: string
+`
+
+// cronBackupCRD is served only in an older version of the group of crontabs,
+// whose highest version is v1.
+const cronBackupCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: cronbackups.stable.example.com
+spec:
+  group: stable.example.com
+  scope: Namespaced
+  names:
+    plural: cronbackups
+    singular: cronbackup
+    kind: CronBackup
+  versions:
+  - name: v1beta1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              retention:
+                description: Number of backups to keep.
+                type: integer
+`
+
+// offlineOptions returns the options exploring the manifests, written to
+// files, or the OpenAPI v3 documents of the latest version if there are none,
+// and the output of them.
+func offlineOptions(t *testing.T, manifests ...string) (*explore.Options, *bytes.Buffer) {
+	t.Helper()
+	var stdout bytes.Buffer
+	o := explore.NewOptions(genericclioptions.IOStreams{
+		In:     &bytes.Buffer{},
+		Out:    &stdout,
+		ErrOut: &bytes.Buffer{},
+	})
+	if len(manifests) == 0 {
+		explore.SetOpenAPIDir(o, openAPISpecV3Directories[k8sVersions[len(k8sVersions)-1]])
+		return o, &stdout
+	}
+	dir := t.TempDir()
+	for i, manifest := range manifests {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, fmt.Sprintf("%d.yaml", i)), []byte(manifest), 0o644))
+	}
+	explore.SetFilenames(o, []string{dir})
+	return o, &stdout
+}
+
+func Test_Run_PreferredVersionPerResource(t *testing.T) {
+	o, stdout := offlineOptions(t, crontabCRD, cronBackupCRD)
+	// The resource missing from the highest version of the group is found
+	// in the version serving it.
+	require.NoError(t, o.Complete(nil, []string{"cronbackups.spec.retention"}))
+	require.NoError(t, o.Run())
+	require.Contains(t, stdout.String(), "VERSION:    v1beta1")
+	require.Contains(t, stdout.String(), "Number of backups to keep.")
+}
+
+// appsCRD returns a CustomResourceDefinition of the plural in apps/v1,
+// a group version of the OpenAPI directory.
+func appsCRD(plural, kind string) string {
+	return fmt.Sprintf(`apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: %[1]s.apps
+spec:
+  group: apps
+  scope: Namespaced
+  names:
+    plural: %[1]s
+    kind: %[2]s
+  versions:
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              size:
+                description: Size of the %[2]s.
+                type: integer
+`, plural, kind)
+}
+
+func Test_Run_OpenAPIDir_CRDs(t *testing.T) {
+	// The documents of the group version are merged, so both the resources
+	// of the directory and of the CustomResourceDefinition are found.
+	for _, inputFieldPath := range []string{"widgets.spec.size", "deployments.spec.replicas"} {
+		t.Run(inputFieldPath, func(t *testing.T) {
+			o, stdout := offlineOptions(t, appsCRD("widgets", "Widget"))
+			explore.SetOpenAPIDir(o, openAPISpecV3Directories[k8sVersions[len(k8sVersions)-1]])
+			require.NoError(t, o.Complete(nil, []string{inputFieldPath}))
+			require.NoError(t, o.Run())
+			require.Contains(t, stdout.String(), "PATH: "+inputFieldPath+"\n")
+		})
+	}
+
+	t.Run("conflict", func(t *testing.T) {
+		o, _ := offlineOptions(t, appsCRD("deployments", "Deployment"))
+		explore.SetOpenAPIDir(o, openAPISpecV3Directories[k8sVersions[len(k8sVersions)-1]])
+		err := o.Complete(nil, []string{"deployments"})
+		require.ErrorContains(t, err, "conflicting path /apis/apps/v1/namespaces/{namespace}/deployments in the OpenAPI v3 documents for apps/v1")
+	})
+}
+
+func Test_Complete_Conflicts(t *testing.T) {
+	tests := []struct {
+		name  string
+		set   func(o *explore.Options)
+		errIs string
+	}{
+		{
+			name:  "multi and tree",
+			set:   func(o *explore.Options) { explore.SetMulti(o, true); explore.SetTree(o, true) },
+			errIs: "--multi and --tree cannot be used together",
+		},
+		{
+			name:  "drill-down and tree",
+			set:   func(o *explore.Options) { explore.SetDrillDown(o, true); explore.SetTree(o, true) },
+			errIs: "--drill-down cannot be used with --multi or --tree",
+		},
+		{
+			name:  "tree and show-details",
+			set:   func(o *explore.Options) { explore.SetTree(o, true); explore.SetShowDetails(o, true) },
+			errIs: "--tree cannot be used with --show-details or --search-descriptions",
+		},
+		{
+			name:  "tree and search-descriptions",
+			set:   func(o *explore.Options) { explore.SetTree(o, true); explore.SetSearchDescriptions(o, true) },
+			errIs: "--tree cannot be used with --show-details or --search-descriptions",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, _ := offlineOptions(t)
+			tt.set(o)
+			require.EqualError(t, o.Complete(nil, nil), tt.errIs)
+		})
+	}
+}
+
+func Test_FieldTree(t *testing.T) {
+	// The paths matching a regex, e.g. "replicas|containers.name", skip the
+	// fields leading to them, which the tree keeps.
+	paths := []string{
+		"deployments.spec.replicas",
+		"deployments.spec.template.spec.containers.name",
+		"deployments.status.replicas",
+	}
+	tests := []struct {
+		name     string
+		expanded []string
+		expect   []string
+	}{
+		{
+			name:   "collapsed",
+			expect: []string{"▸ deployments"},
+		},
+		{
+			name:     "expanded resource",
+			expanded: []string{"deployments"},
+			expect: []string{
+				"▾ deployments",
+				"  ▸ spec",
+				"  ▸ status",
+			},
+		},
+		{
+			name: "expanded to a leaf",
+			expanded: []string{
+				"deployments",
+				"deployments.spec",
+				"deployments.spec.template",
+				"deployments.spec.template.spec",
+				"deployments.spec.template.spec.containers",
+			},
+			expect: []string{
+				"▾ deployments",
+				"  ▾ spec",
+				"      replicas",
+				"    ▾ template",
+				"      ▾ spec",
+				"        ▾ containers",
+				"            name",
+				"  ▸ status",
+			},
+		},
+		{
+			// The children of a collapsed node are hidden even if they are expanded.
+			name:     "collapsed parent",
+			expanded: []string{"deployments.spec"},
+			expect:   []string{"▸ deployments"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expect, explore.FieldTree(paths, tt.expanded))
+		})
+	}
+}
+
+func Test_TreeSelect(t *testing.T) {
+	paths := []string{
+		"deployments.spec.replicas",
+		"deployments.spec.template.spec.containers.name",
+		"deployments.status.replicas",
+	}
+	deploymentsItems := []string{"..", ".", "  ▸ spec", "  ▸ status"}
+	specItems := []string{"..", ".", "      replicas", "    ▸ template"}
+	tests := []struct {
+		name        string
+		selections  []int
+		expectItems [][]string
+		expectPrint string
+	}{
+		{
+			// The search is scoped to the children of the expanded field.
+			name:        "expand and print a child",
+			selections:  []int{2, 2},
+			expectItems: [][]string{deploymentsItems, specItems},
+			expectPrint: "deployments.spec.replicas",
+		},
+		{
+			// An object is printed with ".".
+			name:        "print an object",
+			selections:  []int{2, 1},
+			expectItems: [][]string{deploymentsItems, specItems},
+			expectPrint: "deployments.spec",
+		},
+		{
+			// The expanded field is kept expanded in the parent, and
+			// collapsed by selecting it.
+			name:       "go up and collapse",
+			selections: []int{2, 0, 2, 1},
+			expectItems: [][]string{
+				deploymentsItems,
+				specItems,
+				{"..", ".", "  ▾ spec", "      replicas", "    ▸ template", "  ▸ status"},
+				deploymentsItems,
+			},
+			expectPrint: "deployments",
+		},
+		{
+			name:       "go up to the resources and collapse",
+			selections: []int{0, 0, 0},
+			expectItems: [][]string{
+				deploymentsItems,
+				{"▾ deployments", "  ▸ spec", "  ▸ status"},
+				{"▸ deployments"},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, printed := explore.TreeSelect(paths, tt.selections)
+			require.Equal(t, tt.expectItems, items)
+			require.Equal(t, tt.expectPrint, printed)
+		})
+	}
+}
+
+func Test_DrillDown(t *testing.T) {
+	paths := []string{
+		"deployments.spec",
+		"deployments.spec.template",
+		"deployments.spec.template.metadata",
+		"deployments.spec.replicas",
+		"deployments.status",
+	}
+	specItems := []string{"..", ".", "  replicas", "▸ template"}
+	templateItems := []string{"..", ".", "  metadata"}
+	tests := []struct {
+		name string
+		// required restricts the paths with --required-only if any.
+		required    []string
+		start       string
+		selections  []int
+		expectItems [][]string
+		expectPrint string
+	}{
+		{
+			name:        "field without children",
+			start:       "deployments.status",
+			expectPrint: "deployments.status",
+		},
+		{
+			name:        "print the level",
+			start:       "deployments.spec",
+			selections:  []int{1},
+			expectItems: [][]string{specItems},
+			expectPrint: "deployments.spec",
+		},
+		{
+			name:        "child without children",
+			start:       "deployments.spec",
+			selections:  []int{2},
+			expectItems: [][]string{specItems},
+			expectPrint: "deployments.spec.replicas",
+		},
+		{
+			name:        "drill down and print a child",
+			start:       "deployments.spec",
+			selections:  []int{3, 2},
+			expectItems: [][]string{specItems, templateItems},
+			expectPrint: "deployments.spec.template.metadata",
+		},
+		{
+			name:        "go up and print",
+			start:       "deployments.spec",
+			selections:  []int{3, 0, 1},
+			expectItems: [][]string{specItems, templateItems, specItems},
+			expectPrint: "deployments.spec",
+		},
+		{
+			// The fields leading to the required field are kept.
+			name:        "required only",
+			required:    []string{"deployments.spec.template.metadata"},
+			start:       "deployments.spec",
+			selections:  []int{2, 2},
+			expectItems: [][]string{{"..", ".", "▸ template"}, templateItems},
+			expectPrint: "deployments.spec.template.metadata",
+		},
+		{
+			name:        "go up to the paths",
+			start:       "deployments.spec",
+			selections:  []int{3, 0, 0, 1},
+			expectItems: [][]string{specItems, templateItems, specItems},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, printed := explore.DrillDown(paths, tt.required, tt.start, tt.selections)
+			require.Equal(t, tt.expectItems, items)
+			require.Equal(t, tt.expectPrint, printed)
+		})
+	}
+}
+
+func Test_FieldSummaries(t *testing.T) {
+	paths := []string{
+		"deployments.spec.replicas",
+		"deployments.spec.template.spec.containers.ports.protocol",
+	}
+	o, _ := offlineOptions(t)
+	require.NoError(t, o.Complete(nil, []string{"deployments"}))
+
+	gvr := schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"}
+	summaries, err := explore.FieldSummaries(o, gvr, true, paths...)
+	require.NoError(t, err)
+	require.Equal(t, "<integer>", summaries["deployments.spec.replicas"])
+	// The enum following the default depends on the version of the documents.
+	require.True(t, strings.HasPrefix(summaries["deployments.spec.template.spec.containers.ports.protocol"], "<string> default=TCP"),
+		summaries["deployments.spec.template.spec.containers.ports.protocol"])
+
+	// The kind not found in the document does not fail the fuzzy finder.
+	summaries, err = explore.FieldSummaries(o, gvr, false, paths...)
+	require.NoError(t, err)
+	require.Equal(t, map[string]string{
+		"deployments.spec.replicas":                                "<integer>",
+		"deployments.spec.template.spec.containers.ports.protocol": "<string>",
+	}, summaries)
+}
+
+// kustomization is a manifest of a kind no API server serves, which is often
+// next to CustomResourceDefinitions.
+const kustomization = `apiVersion: kustomize.config.k8s.io/v1beta1
+kind: Kustomization
+resources:
+- crontab.yaml
+`
+
+func Test_Run_CRDs(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "crontab.yaml"), []byte(crontabCRD), 0o644))
+	// The manifests other than CustomResourceDefinitions must be ignored.
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte(kustomization), 0o644))
+	tests := []struct {
+		inputFieldPath string
+		showBrackets   bool
+		hasEnum        bool
+		output         string
+		expectKeywords []string
+	}{
+		{
+			inputFieldPath: "ct.*cronSpec",
+			expectKeywords: []string{
+				"GROUP:      stable.example.com",
+				"KIND:       CronTab",
+				"VERSION:    v1",
+				"PATH: crontabs.spec.cronSpec",
+				"Schedule in Cron format.",
+				"CONSTRAINTS:\n  maxLength\t64\n",
+			},
+		},
+		{
+			// The keys of the map come between the map and its fields.
+			inputFieldPath: "ct.*sidecars.image",
+			output:         "jsonpath",
+			expectKeywords: []string{
+				"kubectl get crontabs.v1.stable.example.com -o jsonpath='{.items[*].spec.sidecars.*.image}'\n",
+			},
+		},
+		{
+			inputFieldPath: "ct.*sidecars.image",
+			output:         "jq",
+			expectKeywords: []string{
+				"kubectl get crontabs.v1.stable.example.com -o json | jq '.items[] | .spec.sidecars[].image'\n",
+			},
+		},
+		{
+			// The constraints and the rules of the items are told apart from
+			// those of the array.
+			inputFieldPath: "ct.*tags",
+			expectKeywords: []string{
+				"CONSTRAINTS:\n  maxItems\t5\n",
+				"ITEM CONSTRAINTS:\n  maxLength\t3\n",
+				"ITEM VALIDATIONS:\n  self == self.lowerAscii()\n    tags must be lowercase\n",
+			},
+		},
+		{
+			inputFieldPath: "ct.*tags",
+			output:         "json",
+			expectKeywords: []string{
+				`"constraints": {
+    "maxItems": 5
+  }`,
+				`"itemConstraints": {
+    "maxLength": 3
+  }`,
+				`"itemValidations": [
+    {
+      "rule": "self == self.lowerAscii()",
+      "message": "tags must be lowercase"
+    }
+  ]`,
+			},
+		},
+		{
+			inputFieldPath: "ct.*port",
+			output:         "skeleton",
+			expectKeywords: []string{
+				"  port: 0\n",
+			},
+		},
+		{
+			inputFieldPath: "ct.*cronSpec",
+			output:         "json",
+			expectKeywords: []string{
+				`"constraints": {
+    "maxLength": 64
+  }`,
+			},
+		},
+		{
+			inputFieldPath: "crontab.spec$",
+			expectKeywords: []string{
+				"Desired state of the CronTab.",
+				"cronSpec\t<string> -required-",
+				"containers\t<[]Object>",
+				"VALIDATIONS:\n  !has(self.image) || self.image != ''\n    image must not be empty\n",
+			},
+		},
+		{
+			inputFieldPath: "crontab.spec$",
+			output:         "yaml",
+			expectKeywords: []string{
+				`validations:
+- message: image must not be empty
+  rule: '!has(self.image) || self.image != ''''`,
+			},
+		},
+		{
+			inputFieldPath: "crontabs.spec.containers.name",
+			showBrackets:   true,
+			expectKeywords: []string{
+				"PATH: crontabs.spec.containers[].name",
+				"Name of the container.",
+			},
+		},
+		{
+			// The enum of an array is that of its items.
+			inputFieldPath: "crontabs.spec.",
+			hasEnum:        true,
+			expectKeywords: []string{
+				"PATH: crontabs.spec.days\n",
+			},
+		},
+		{
+			inputFieldPath: "crontabs.spec.containers$",
+			expectKeywords: []string{
+				"EXTENSIONS:\n  x-kubernetes-list-type\tmap\n  x-kubernetes-list-map-keys\t[name]\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("inputFieldPath: %s", tt.inputFieldPath), func(t *testing.T) {
+			var stdout bytes.Buffer
+			opts := explore.NewOptions(genericclioptions.IOStreams{
+				In:     &bytes.Buffer{},
+				Out:    &stdout,
+				ErrOut: &bytes.Buffer{},
+			})
+			explore.SetFilenames(opts, []string{dir})
+			explore.SetShowBrackets(opts, tt.showBrackets)
+			explore.SetHasEnum(opts, tt.hasEnum)
+			if tt.output != "" {
+				explore.SetOutput(opts, tt.output)
+			}
+			require.NoError(t, opts.Complete(nil, []string{tt.inputFieldPath}))
+			require.NoError(t, opts.Run())
+			for _, keyword := range tt.expectKeywords {
+				require.Contains(t, stdout.String(), keyword)
+			}
+		})
+	}
+}
+
+const crontabManifest = `apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  name: my-crontab
+spec:
+  cronSpec: "*/5 * * * *"
+  containers:
+  - name: app
+  sidecars:
+    logger:
+      image: fluentd
+    proxy:
+      image: envoy
+`
+
+func Test_Run_SetOnly(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "crontab.yaml"), []byte(crontabCRD+"---\n"+crontabManifest), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "kustomization.yaml"), []byte(kustomization), 0o644))
+	tests := []struct {
+		inputFieldPath string
+		expectKeywords []string
+		expectErr      bool
+	}{
+		{
+			inputFieldPath: "crontabs.spec.cronSpec",
+			expectKeywords: []string{
+				"PATH: crontabs.spec.cronSpec",
+				`VALUE: '*/5 * * * *'`,
+			},
+		},
+		{
+			inputFieldPath: "crontabs.spec.containers.name",
+			expectKeywords: []string{
+				"PATH: crontabs.spec.containers.name",
+				"VALUE: - app\n",
+			},
+		},
+		{
+			// The fields of the values of a map are walked through all the values.
+			inputFieldPath: "crontabs.spec.sidecars.image",
+			expectKeywords: []string{
+				"PATH: crontabs.spec.sidecars.image",
+				"VALUE:\n  - fluentd\n  - envoy\n",
+			},
+		},
+		{
+			// spec.image is not set on the manifest.
+			inputFieldPath: "crontabs.spec.image",
+			expectErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("inputFieldPath: %s", tt.inputFieldPath), func(t *testing.T) {
+			var stdout, errOut bytes.Buffer
+			opts := explore.NewOptions(genericclioptions.IOStreams{
+				In:     &bytes.Buffer{},
+				Out:    &stdout,
+				ErrOut: &errOut,
+			})
+			explore.SetFilenames(opts, []string{dir})
+			explore.SetSetOnly(opts, true)
+			require.NoError(t, opts.Complete(nil, []string{tt.inputFieldPath}))
+			require.Contains(t, errOut.String(), "Warning: skip the manifest of kustomize.config.k8s.io/v1beta1, Kind=Kustomization: no resource found")
+			if tt.expectErr {
+				require.Error(t, opts.Run())
+				return
+			}
+			require.NoError(t, opts.Run())
+			for _, keyword := range tt.expectKeywords {
+				require.Contains(t, stdout.String(), keyword)
+			}
+		})
+	}
+}
+
+func Test_Run_CacheDir(t *testing.T) {
+	version := k8sVersions[len(k8sVersions)-1]
+	fakeServer, err := clienttestutil.NewFakeOpenAPIV3Server(openAPISpecV3Directories[version])
+	require.NoError(t, err)
+	t.Cleanup(fakeServer.HttpServer.Close)
+	fakeDiscoveryClient := discovery.NewDiscoveryClientForConfigOrDie(&rest.Config{Host: fakeServer.HttpServer.URL})
+	fakeCachedDiscoveryClient := cmdtesting.NewFakeCachedDiscoveryClient()
+	fakeCachedDiscoveryClient.PreferredResources = []*v1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []v1.APIResource{
+				{
+					Name:         "nodes",
+					SingularName: "node",
+					Namespaced:   false,
+					Kind:         "Node",
+					ShortNames:   []string{"no"},
+				},
+			},
+		},
+	}
+	cacheDir := t.TempDir()
+	run := func(refreshCache bool) (string, error) {
+		tf := cmdtesting.NewTestFactory()
+		defer tf.Cleanup()
+		tf.WithDiscoveryClient(fakeCachedDiscoveryClient)
+		tf.OpenAPIV3ClientFunc = func() (openapiclient.Client, error) {
+			return fakeDiscoveryClient.OpenAPIV3(), nil
+		}
+		tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+		var stdout bytes.Buffer
+		opts := explore.NewOptions(genericclioptions.IOStreams{
+			In:     &bytes.Buffer{},
+			Out:    &stdout,
+			ErrOut: &bytes.Buffer{},
+		})
+		explore.SetCacheDir(opts, cacheDir)
+		explore.SetRefreshCache(opts, refreshCache)
+		require.NoError(t, opts.Complete(tf, []string{"nodes.spec.providerID"}))
+		err := opts.Run()
+		return stdout.String(), err
+	}
+	cachedFiles := func() []string {
+		files, err := filepath.Glob(filepath.Join(cacheDir, "api", "v1", "*.json"))
+		require.NoError(t, err)
+		return files
+	}
+
+	first, err := run(false)
+	require.NoError(t, err)
+	require.Contains(t, first, "PATH: nodes.spec.providerID")
+	files := cachedFiles()
+	require.Len(t, files, 1)
+
+	// The document is served from the cache, so breaking it breaks the explanation.
+	require.NoError(t, os.WriteFile(files[0], []byte(`{}`), 0o644))
+	_, err = run(false)
+	require.Error(t, err)
+
+	// --refresh-cache discards the broken document.
+	refreshed, err := run(true)
+	require.NoError(t, err)
+	require.Equal(t, first, refreshed)
+	require.Len(t, cachedFiles(), 1)
+}
+
+func Test_Run_OpenAPIV3Only(t *testing.T) {
+	version := k8sVersions[len(k8sVersions)-1]
+	fakeServer, err := clienttestutil.NewFakeOpenAPIV3Server(openAPISpecV3Directories[version])
+	require.NoError(t, err)
+	t.Cleanup(fakeServer.HttpServer.Close)
+	fakeDiscoveryClient := discovery.NewDiscoveryClientForConfigOrDie(&rest.Config{Host: fakeServer.HttpServer.URL})
+	fakeCachedDiscoveryClient := cmdtesting.NewFakeCachedDiscoveryClient()
+	fakeCachedDiscoveryClient.PreferredResources = []*v1.APIResourceList{
+		{
+			GroupVersion: "apps/v1",
+			APIResources: []v1.APIResource{
+				{
+					Name:         "deployments",
+					SingularName: "deployment",
+					Namespaced:   true,
+					Kind:         "Deployment",
+					ShortNames:   []string{"deploy"},
+				},
+			},
+		},
+	}
+	tf := cmdtesting.NewTestFactory()
+	defer tf.Cleanup()
+	tf.WithDiscoveryClient(fakeCachedDiscoveryClient)
+	tf.OpenAPIV3ClientFunc = func() (openapiclient.Client, error) {
+		return fakeDiscoveryClient.OpenAPIV3(), nil
+	}
+	// The fields are walked in the OpenAPI v3 document, so the OpenAPI v2
+	// document, which is slow to fetch and not cached, must not be used.
+	tf.OpenAPISchemaFunc = func() (openapi.Resources, error) {
+		return nil, fmt.Errorf("OpenAPI v2 must not be fetched")
+	}
+	tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+	var stdout bytes.Buffer
+	opts := explore.NewOptions(genericclioptions.IOStreams{
+		In:     &bytes.Buffer{},
+		Out:    &stdout,
+		ErrOut: &bytes.Buffer{},
+	})
+	explore.SetCacheDir(opts, t.TempDir())
+	// template and spec are wrapped in allOf in OpenAPI v3, which the walk
+	// must see through to reach the containers.
+	require.NoError(t, opts.Complete(tf, []string{"deployments.spec.template.spec.containers.name$"}))
+	require.NoError(t, opts.Run())
+	require.Contains(t, stdout.String(), "PATH: deployments.spec.template.spec.containers.name")
+}
+
+func Test_Diff(t *testing.T) {
+	for _, version := range k8sVersions {
+		t.Run(fmt.Sprintf("version: %s", version), func(t *testing.T) {
+			var stdout bytes.Buffer
+			opts := explore.NewDiffOptions(explore.NewOptions(genericclioptions.IOStreams{
+				In:     &bytes.Buffer{},
+				Out:    &stdout,
+				ErrOut: &bytes.Buffer{},
+			}))
+			explore.SetOpenAPIDir(opts.Options, openAPISpecV3Directories[version])
+			explore.SetDiffFrom(opts, "autoscaling/v1")
+			explore.SetDiffTo(opts, "autoscaling/v2")
+			require.NoError(t, opts.Complete(nil, []string{"horizontalpodautoscaler"}))
+			require.NoError(t, opts.Run())
+			for _, keyword := range []string{
+				"FROM: autoscaling/v1",
+				"TO:   autoscaling/v2",
+				"REMOVED:\n  horizontalpodautoscalers.spec.targetCPUUtilizationPercentage\t<integer>",
+				"horizontalpodautoscalers.spec.metrics\t<[]Object>",
+				"metrics contains the specifications",
+			} {
+				require.Contains(t, stdout.String(), keyword)
+			}
+			// The fields under an added field are covered by it.
+			require.NotContains(t, stdout.String(), "horizontalpodautoscalers.spec.metrics.type")
+		})
+	}
+}
+
+func Test_Compare(t *testing.T) {
+	// Pod-level resources were added to PodSpec in 1.32, and the source of
+	// PodResourceClaim was removed in 1.31.
+	oldVersion, newVersion := "1.27", "1.34"
+	fakeCachedDiscoveryClient := cmdtesting.NewFakeCachedDiscoveryClient()
+	fakeCachedDiscoveryClient.PreferredResources = []*v1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []v1.APIResource{
+				{
+					Name:         "pods",
+					SingularName: "pod",
+					Namespaced:   true,
+					Kind:         "Pod",
+					ShortNames:   []string{"po"},
+				},
+			},
+		},
+	}
+	fakeCachedDiscoveryClient.DiscoveryInterface = &fakeDiscoveryClientExtension{
+		FakeCachedDiscoveryClient: fakeCachedDiscoveryClient,
+	}
+	factories := make(map[string]cmdutil.Factory)
+	for _, version := range []string{oldVersion, newVersion} {
+		fakeServer, err := clienttestutil.NewFakeOpenAPIV3Server(openAPISpecV3Directories[version])
+		require.NoError(t, err)
+		t.Cleanup(fakeServer.HttpServer.Close)
+		fakeDiscoveryClient := discovery.NewDiscoveryClientForConfigOrDie(&rest.Config{Host: fakeServer.HttpServer.URL})
+		tf := cmdtesting.NewTestFactory()
+		t.Cleanup(tf.Cleanup)
+		tf.WithDiscoveryClient(fakeCachedDiscoveryClient)
+		tf.OpenAPIV3ClientFunc = func() (openapiclient.Client, error) {
+			return fakeDiscoveryClient.OpenAPIV3(), nil
+		}
+		tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+		factories[version] = tf
+	}
+
+	var stdout bytes.Buffer
+	opts := explore.NewCompareOptions(explore.NewOptions(genericclioptions.IOStreams{
+		In:     &bytes.Buffer{},
+		Out:    &stdout,
+		ErrOut: &bytes.Buffer{},
+	}))
+	explore.SetCompareContexts(opts, []string{oldVersion, newVersion})
+	explore.SetCompareFactory(opts, func(context string) cmdutil.Factory {
+		return factories[context]
+	})
+	require.NoError(t, opts.Complete([]string{"pods"}))
+	require.NoError(t, opts.Run())
+	require.Contains(t, stdout.String(), "RESOURCE: /v1, Resource=pods")
+	require.Contains(t, stdout.String(), "ONLY IN 1.34:\n")
+	require.Contains(t, stdout.String(), "pods.spec.resources\t<Object>")
+	require.Contains(t, stdout.String(), "ONLY IN 1.27:\n")
+	require.Contains(t, stdout.String(), "pods.spec.resourceClaims.source\t<Object>")
+}
+
+func Test_Compare_Missing(t *testing.T) {
+	version := k8sVersions[len(k8sVersions)-1]
+	fakeServer, err := clienttestutil.NewFakeOpenAPIV3Server(openAPISpecV3Directories[version])
+	require.NoError(t, err)
+	t.Cleanup(fakeServer.HttpServer.Close)
+	fakeDiscoveryClient := discovery.NewDiscoveryClientForConfigOrDie(&rest.Config{Host: fakeServer.HttpServer.URL})
+	resources := map[string]v1.APIResource{
+		"pods":  {Name: "pods", SingularName: "pod", Namespaced: true, Kind: "Pod", ShortNames: []string{"po"}},
+		"nodes": {Name: "nodes", SingularName: "node", Namespaced: false, Kind: "Node", ShortNames: []string{"no"}},
+	}
+	// The pods are served only in the context "new".
+	served := map[string][]string{
+		"old": {"nodes"},
+		"new": {"nodes", "pods"},
+	}
+	factories := make(map[string]cmdutil.Factory)
+	for context, names := range served {
+		list := &v1.APIResourceList{GroupVersion: "v1"}
+		for _, name := range names {
+			list.APIResources = append(list.APIResources, resources[name])
+		}
+		fakeCachedDiscoveryClient := cmdtesting.NewFakeCachedDiscoveryClient()
+		fakeCachedDiscoveryClient.PreferredResources = []*v1.APIResourceList{list}
+		fakeCachedDiscoveryClient.DiscoveryInterface = &fakeDiscoveryClientExtension{
+			FakeCachedDiscoveryClient: fakeCachedDiscoveryClient,
+		}
+		tf := cmdtesting.NewTestFactory()
+		t.Cleanup(tf.Cleanup)
+		tf.WithDiscoveryClient(fakeCachedDiscoveryClient)
+		tf.OpenAPIV3ClientFunc = func() (openapiclient.Client, error) {
+			return fakeDiscoveryClient.OpenAPIV3(), nil
+		}
+		tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+		factories[context] = tf
+	}
+
+	var stdout bytes.Buffer
+	opts := explore.NewCompareOptions(explore.NewOptions(genericclioptions.IOStreams{
+		In:     &bytes.Buffer{},
+		Out:    &stdout,
+		ErrOut: &bytes.Buffer{},
+	}))
+	explore.SetCompareContexts(opts, []string{"old", "new"})
+	explore.SetCompareFactory(opts, func(context string) cmdutil.Factory {
+		return factories[context]
+	})
+	require.NoError(t, opts.Complete([]string{"pods"}))
+	require.NoError(t, opts.Run())
+	require.Equal(t, "RESOURCE: /v1, Resource=pods\nMISSING IN old\n", stdout.String())
+}
+
+func Test_Compare_WithContext(t *testing.T) {
+	flags := genericclioptions.NewConfigFlags(true)
+	namespace, user, server, timeout := "my-namespace", "my-user", "https://example.com", "5s"
+	flags.Namespace = &namespace
+	flags.AuthInfoName = &user
+	flags.APIServer = &server
+	flags.Timeout = &timeout
+
+	cloned := explore.WithContext(flags, "other")
+	require.Equal(t, "other", *cloned.Context)
+	require.Equal(t, namespace, *cloned.Namespace)
+	require.Equal(t, user, *cloned.AuthInfoName)
+	require.Equal(t, server, *cloned.APIServer)
+	require.Equal(t, timeout, *cloned.Timeout)
+}
+
+func Test_Validate(t *testing.T) {
+	tests := []struct {
+		name           string
+		manifest       string
+		showBrackets   bool
+		expectProblems int
+		expectKeywords []string
+	}{
+		{
+			name:     "valid",
+			manifest: crontabManifest,
+			expectKeywords: []string{
+				"crontabs.stable.example.com/my-crontab: valid",
+			},
+		},
+		{
+			name: "invalid",
+			manifest: `apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  name: my-crontab
+spec:
+  imagee: nginx
+  containers:
+  - name: 1
+`,
+			showBrackets:   true,
+			expectProblems: 3,
+			expectKeywords: []string{
+				"crontabs.stable.example.com/my-crontab:\n",
+				"  crontabs.spec.cronSpec: missing required field\n",
+				"  crontabs.spec.imagee: unknown field, did you mean crontabs.spec.image?\n",
+				"  crontabs.spec.containers[].name: expected <string>, got <integer>\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "crontab.yaml"), []byte(crontabCRD+"---\n"+tt.manifest), 0o644))
+			var stdout bytes.Buffer
+			opts := explore.NewValidateOptions(explore.NewOptions(genericclioptions.IOStreams{
+				In:     &bytes.Buffer{},
+				Out:    &stdout,
+				ErrOut: &bytes.Buffer{},
+			}))
+			explore.SetFilenames(opts.Options, []string{dir})
+			explore.SetShowBrackets(opts.Options, tt.showBrackets)
+			require.NoError(t, opts.Complete(nil))
+			if tt.expectProblems > 0 {
+				require.EqualError(t, opts.Run(), fmt.Sprintf("%d problems found", tt.expectProblems))
+			} else {
+				require.NoError(t, opts.Run())
+			}
+			for _, keyword := range tt.expectKeywords {
+				require.Contains(t, stdout.String(), keyword)
+			}
+		})
+	}
+}
+
+func Test_LSP(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "crontab.yaml"), []byte(crontabCRD), 0o644))
+	text := `apiVersion: stable.example.com/v1
+kind: CronTab
+metadata:
+  name: my-crontab
+spec:
+  cronSpec: "*/5 * * * *"
+  imagee: nginx
+  containers:
+  - 
+  - {name: "😀", namee: app}
+`
+	uri := "file:///crontab.yaml"
+	var stdin bytes.Buffer
+	for _, msg := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`,
+		fmt.Sprintf(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":%q,"text":%q}}}`, uri, text),
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":2,"method":"textDocument/completion","params":{"textDocument":{"uri":%q},"position":{"line":8,"character":4}}}`, uri),
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":3,"method":"textDocument/hover","params":{"textDocument":{"uri":%q},"position":{"line":5,"character":4}}}`, uri),
+		// A position out of the line must not crash the server.
+		fmt.Sprintf(`{"jsonrpc":"2.0","id":5,"method":"textDocument/completion","params":{"textDocument":{"uri":%q},"position":{"line":8,"character":-1}}}`, uri),
+		`{"jsonrpc":"2.0","id":4,"method":"shutdown"}`,
+		`{"jsonrpc":"2.0","method":"exit"}`,
+	} {
+		fmt.Fprintf(&stdin, "Content-Length: %d\r\n\r\n%s", len(msg), msg)
+	}
+	var stdout bytes.Buffer
+	opts := explore.NewLSPOptions(explore.NewOptions(genericclioptions.IOStreams{
+		In:     &stdin,
+		Out:    &stdout,
+		ErrOut: &bytes.Buffer{},
+	}))
+	explore.SetFilenames(opts.Options, []string{dir})
+	require.NoError(t, opts.Complete(nil))
+	require.NoError(t, opts.Run())
+
+	messages := make(map[string]string)
+	for _, frame := range strings.Split(stdout.String(), "Content-Length: ")[1:] {
+		_, body, ok := strings.Cut(frame, "\r\n\r\n")
+		require.True(t, ok)
+		var msg struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(body), &msg))
+		messages[string(msg.ID)+msg.Method] = body
+	}
+	require.Contains(t, messages["1"], `"hoverProvider":true`)
+	require.Contains(t, messages["textDocument/publishDiagnostics"], `"start":{"line":6,"character":2}`)
+	require.Contains(t, messages["textDocument/publishDiagnostics"], `unknown field \"imagee\", did you mean crontabs.spec.image?`)
+	// The emoji takes 2 UTF-16 code units and 4 bytes.
+	require.Contains(t, messages["textDocument/publishDiagnostics"], `"start":{"line":9,"character":17},"end":{"line":9,"character":22}`)
+	require.Contains(t, messages["2"], `"label":"name","kind":10,"detail":"\u003cstring\u003e","documentation":"Name of the container."`)
+	require.Contains(t, messages["3"], `PATH: crontabs.spec.cronSpec`)
+	require.Contains(t, messages["3"], `Schedule in Cron format.`)
+	require.Contains(t, messages["4"], `"result":null`)
+	require.Contains(t, messages["5"], `"result":[`)
+}
+
+func Test_Serve(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "crontab.yaml"), []byte(crontabCRD), 0o644))
+	opts := explore.NewServeOptions(explore.NewOptions(genericclioptions.IOStreams{
+		In:     &bytes.Buffer{},
+		Out:    &bytes.Buffer{},
+		ErrOut: &bytes.Buffer{},
+	}))
+	explore.SetFilenames(opts.Options, []string{dir})
+	require.NoError(t, opts.Complete(nil))
+	server := httptest.NewServer(explore.ServeHandler(opts))
+	t.Cleanup(server.Close)
+	tests := []struct {
+		path           string
+		expectStatus   int
+		expectKeywords []string
+	}{
+		{
+			path:         "/",
+			expectStatus: http.StatusOK,
+			expectKeywords: []string{
+				`<summary>stable.example.com</summary>`,
+				`href="/stable.example.com/v1/crontabs"`,
+			},
+		},
+		{
+			path:         "/stable.example.com/v1/crontabs",
+			expectStatus: http.StatusOK,
+			expectKeywords: []string{
+				"CronTab runs a command periodically.",
+				`<details data-path="spec"><summary><a href="/stable.example.com/v1/crontabs/spec">spec</a>`,
+			},
+		},
+		{
+			path:         "/stable.example.com/v1/crontabs/spec.containers.name",
+			expectStatus: http.StatusOK,
+			expectKeywords: []string{
+				"PATH: crontabs.spec.containers.name",
+				"Name of the container.",
+				`<details data-path="spec.containers" open>`,
+				`<a class="leaf selected" data-path="spec.containers.name" href="/stable.example.com/v1/crontabs/spec.containers.name">name <span class="type">&lt;string&gt;</span></a>`,
+			},
+		},
+		{
+			path:         "/stable.example.com/v1/crontabs/spec.unknown",
+			expectStatus: http.StatusNotFound,
+		},
+		{
+			path:         "/stable.example.com/v1/unknowns",
+			expectStatus: http.StatusNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp, err := http.Get(server.URL + tt.path)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			require.NoError(t, err)
+			require.Equal(t, tt.expectStatus, resp.StatusCode)
+			for _, keyword := range tt.expectKeywords {
+				require.Contains(t, string(body), keyword)
+			}
+		})
+	}
+}
+
+func Test_Docs(t *testing.T) {
+	// A pipe in a description must not break the table.
+	crd := strings.Replace(crontabCRD, "Container image to run.", "Container image to run, e.g. nginx|busybox.", 1)
+	tests := []struct {
+		showBrackets   bool
+		expectKeywords []string
+	}{
+		{
+			expectKeywords: []string{
+				"# CronTab\n",
+				"CronTab runs a command periodically.\n",
+				"| Path | Type | Required | Description |\n",
+				"| `spec` | `Object` |  | Desired state of the CronTab. |\n",
+				"| `spec.cronSpec` | `string` | yes | Schedule in Cron format. |\n",
+				"| `spec.image` | `string` |  | Container image to run, e.g. nginx\\|busybox. |\n",
+				"| `spec.containers` | `[]Object` |  |  |\n",
+				"| `spec.containers.name` | `string` |  | Name of the container. |\n",
+			},
+		},
+		{
+			showBrackets: true,
+			expectKeywords: []string{
+				"| `spec.containers[]` | `[]Object` |  |  |\n",
+				"| `spec.containers[].name` | `string` |  | Name of the container. |\n",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("showBrackets: %t", tt.showBrackets), func(t *testing.T) {
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "crontab.yaml"), []byte(crd), 0o644))
+			out := t.TempDir()
+			var stdout bytes.Buffer
+			opts := explore.NewDocsOptions(explore.NewOptions(genericclioptions.IOStreams{
+				In:     &bytes.Buffer{},
+				Out:    &stdout,
+				ErrOut: &bytes.Buffer{},
+			}))
+			explore.SetFilenames(opts.Options, []string{dir})
+			explore.SetShowBrackets(opts.Options, tt.showBrackets)
+			explore.SetDocsOut(opts, out)
+			require.NoError(t, opts.Complete(nil))
+			require.NoError(t, opts.Run())
+			require.Contains(t, stdout.String(), "Wrote 1 pages and index.md to "+out)
+
+			index, err := os.ReadFile(filepath.Join(out, "index.md"))
+			require.NoError(t, err)
+			require.Contains(t, string(index), "## stable.example.com\n\n- [CronTab](stable.example.com/v1/crontabs.md) (`crontabs`, v1)\n")
+
+			page, err := os.ReadFile(filepath.Join(out, "stable.example.com", "v1", "crontabs.md"))
+			require.NoError(t, err)
+			for _, keyword := range tt.expectKeywords {
+				require.Contains(t, string(page), keyword)
+			}
+		})
+	}
+}
+
+func Test_Uses(t *testing.T) {
+	tests := []struct {
+		definition     string
+		expectErr      bool
+		expectKeywords []string
+	}{
+		{
+			definition: "io.k8s.api.core.v1.PodTemplateSpec",
+			expectKeywords: []string{
+				"DEFINITION: io.k8s.api.core.v1.PodTemplateSpec\n",
+				"\ndeployments.v1.apps:\n  deployments.spec.template\n",
+				"\npodtemplates:\n  podtemplates.template\n",
+			},
+		},
+		{
+			// The name is matched by its last segments.
+			definition: "PodSpec",
+			expectKeywords: []string{
+				"DEFINITION: io.k8s.api.core.v1.PodSpec\n",
+				"  pods.spec\n",
+			},
+		},
+		{
+			definition: "io.k8s.api.core.v1.NoSuchDefinition",
+			expectErr:  true,
+		},
+	}
+	version := k8sVersions[len(k8sVersions)-1]
+	for _, tt := range tests {
+		t.Run(tt.definition, func(t *testing.T) {
+			var stdout bytes.Buffer
+			opts := explore.NewUsesOptions(explore.NewOptions(genericclioptions.IOStreams{
+				In:     &bytes.Buffer{},
+				Out:    &stdout,
+				ErrOut: &bytes.Buffer{},
+			}))
+			explore.SetOpenAPIDir(opts.Options, openAPISpecV3Directories[version])
+			require.NoError(t, opts.Complete(nil, []string{tt.definition}))
+			err := opts.Run()
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			for _, keyword := range tt.expectKeywords {
+				require.Contains(t, stdout.String(), keyword)
+			}
+		})
+	}
+}
+
+func Test_Definitions(t *testing.T) {
+	tests := []struct {
+		definition     string
+		expectErr      bool
+		expectKeywords []string
+	}{
+		{
+			// A definition without fields is explained without the fuzzy finder.
+			definition: "api.resource.Quantity$",
+			expectKeywords: []string{
+				"PATH: Quantity\n",
+				"DEFINITION: io.k8s.apimachinery.pkg.api.resource.Quantity\n",
+				"KIND:       Quantity\n",
+				"Quantity is a fixed-point representation of a number.",
+			},
+		},
+		{
+			definition: "io.k8s.api.core.v1.NoSuchDefinition",
+			expectErr:  true,
+		},
+	}
+	version := k8sVersions[len(k8sVersions)-1]
+	for _, tt := range tests {
+		t.Run(tt.definition, func(t *testing.T) {
+			var stdout bytes.Buffer
+			opts := explore.NewDefinitionsOptions(explore.NewOptions(genericclioptions.IOStreams{
+				In:     &bytes.Buffer{},
+				Out:    &stdout,
+				ErrOut: &bytes.Buffer{},
+			}))
+			explore.SetOpenAPIDir(opts.Options, openAPISpecV3Directories[version])
+			require.NoError(t, opts.Complete(nil, []string{tt.definition}))
+			err := opts.Run()
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			for _, keyword := range tt.expectKeywords {
+				require.Contains(t, stdout.String(), keyword)
+			}
+		})
+	}
+}
+
+const deprecatedCrontabCRD = `apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: crontabs.stable.example.com
+spec:
+  group: stable.example.com
+  scope: Namespaced
+  names:
+    plural: crontabs
+    singular: crontab
+    kind: CronTab
+  versions:
+  - name: v1beta1
+    served: true
+    storage: false
+    deprecated: true
+    deprecationWarning: stable.example.com/v1beta1 CronTab is deprecated; use stable.example.com/v1 CronTab
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              cronSpec:
+                type: string
+  - name: v1
+    served: true
+    storage: true
+    schema:
+      openAPIV3Schema:
+        type: object
+        properties:
+          spec:
+            type: object
+            properties:
+              cronSpec:
+                type: string
+              image:
+                description: Container image to run. Deprecated; use containers instead.
+                type: string
+`
+
+func Test_Definitions_Output(t *testing.T) {
+	const labelSelector = "io.k8s.apimachinery.pkg.apis.meta.v1.LabelSelector"
+	tests := []struct {
+		name string
+		// definition is LabelSelector and path is its matchExpressions.values if empty.
+		definition     string
+		path           string
+		output         string
+		showBrackets   bool
+		expectErr      bool
+		expectKeywords []string
+	}{
+		{
+			name:         "plaintext",
+			output:       "plaintext",
+			showBrackets: true,
+			expectKeywords: []string{
+				"DEFINITION: io.k8s.apimachinery.pkg.apis.meta.v1.LabelSelector\n",
+				"PATH: LabelSelector.matchExpressions[].values[]\n",
+				"KIND:       LabelSelector\n",
+				"VERSION:    v1\n",
+				"FIELD: values <[]string>",
+			},
+		},
+		{
+			name:   "json",
+			output: "json",
+			expectKeywords: []string{
+				`"path": "LabelSelector.matchExpressions.values"`,
+				`"kind": "LabelSelector"`,
+				`"type": "[]string"`,
+			},
+		},
+		{
+			name:           "yaml",
+			output:         "yaml",
+			expectKeywords: []string{"path: LabelSelector.matchExpressions.values\n"},
+		},
+		{
+			// The root describes the definition itself.
+			name:       "json of the root",
+			definition: "io.k8s.apimachinery.pkg.api.resource.Quantity",
+			path:       "Quantity",
+			output:     "json",
+			expectKeywords: []string{
+				`"name": "Quantity"`,
+				`"path": "Quantity"`,
+				`"kind": "Quantity"`,
+				`"description": "Quantity is a fixed-point representation of a number.`,
+			},
+		},
+		{
+			name:   "yaml of the root",
+			path:   "LabelSelector",
+			output: "yaml",
+			expectKeywords: []string{
+				"name: LabelSelector\n",
+				"kind: LabelSelector\n",
+				"fields:\n",
+				"  name: matchExpressions\n",
+				"  name: matchLabels\n",
+			},
+		},
+		{
+			// A definition is not a resource to write a manifest of.
+			name:      "skeleton",
+			output:    "skeleton",
+			expectErr: true,
+		},
+		{
+			// A definition is not a resource to query.
+			name:      "jsonpath",
+			output:    "jsonpath",
+			expectErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			o, stdout := offlineOptions(t)
+			explore.SetOutput(o, tt.output)
+			explore.SetShowBrackets(o, tt.showBrackets)
+			definition := labelSelector
+			if tt.definition != "" {
+				definition = tt.definition
+			}
+			original, withBrackets := "LabelSelector.matchExpressions.values", "LabelSelector.matchExpressions[].values[]"
+			if tt.path != "" {
+				original, withBrackets = tt.path, tt.path
+			}
+			opts := explore.NewDefinitionsOptions(o)
+			err := opts.Complete(nil, []string{definition})
+			if tt.expectErr {
+				require.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			require.NoError(t, explore.PrintDefinition(opts, definition, original, withBrackets))
+			for _, keyword := range tt.expectKeywords {
+				require.Contains(t, stdout.String(), keyword)
+			}
+		})
+	}
+}
+
+func Test_Deprecations(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "crontab.yaml"), []byte(deprecatedCrontabCRD), 0o644))
+	var stdout bytes.Buffer
+	opts := explore.NewDeprecationsOptions(explore.NewOptions(genericclioptions.IOStreams{
+		In:     &bytes.Buffer{},
+		Out:    &stdout,
+		ErrOut: &bytes.Buffer{},
+	}))
+	explore.SetFilenames(opts.Options, []string{dir})
+	require.NoError(t, opts.Complete(nil))
+	require.NoError(t, opts.Run())
+	require.Equal(t, "DEPRECATED API VERSIONS:\n"+
+		"  stable.example.com/v1beta1 crontabs\tuse stable.example.com/v1\n"+
+		"    stable.example.com/v1beta1 CronTab is deprecated; use stable.example.com/v1 CronTab\n"+
+		"\n"+
+		"DEPRECATED FIELDS:\n"+
+		"  crontabs.spec.image\tstable.example.com/v1\n"+
+		"    Deprecated; use containers instead.\n",
+		stdout.String())
+}
+
+func Test_DeprecationNote(t *testing.T) {
+	tests := []struct {
+		description      string
+		expectDeprecated bool
+		expectNote       string
+	}{
+		{
+			description: "persistentVolumeReclaimPolicy defines what happens to a persistent volume when released from its claim. " +
+				"Valid options are Retain (default for manually created PersistentVolumes), Delete (default for dynamically provisioned PersistentVolumes), and Recycle (deprecated). " +
+				"Recycle must be supported by the volume plugin underlying this PersistentVolume.",
+		},
+		{
+			description:      "DeprecatedServiceAccount is a deprecated alias for ServiceAccountName. Deprecated: Use serviceAccountName instead.",
+			expectDeprecated: true,
+			expectNote:       "Deprecated: Use serviceAccountName instead.",
+		},
+		{
+			description:      "The name of the cluster. This field is deprecated. It is set by the system.",
+			expectDeprecated: true,
+			expectNote:       "This field is deprecated.",
+		},
+		{
+			description:      "Deprecated; use containers instead.",
+			expectDeprecated: true,
+			expectNote:       "Deprecated; use containers instead.",
+		},
+		{
+			// e.g. nodes.status.phase, whose URL ends a line without a period.
+			description:      "NodePhase is the recently observed lifecycle phase of the node. More info: https://kubernetes.io/docs/concepts/nodes/node/#phase\nThe field is never populated, and now is deprecated.",
+			expectDeprecated: true,
+			expectNote:       "The field is never populated, and now is deprecated.",
+		},
+		{
+			description: "Whether the deprecated fields are pruned.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.description, func(t *testing.T) {
+			note, deprecated := explore.DeprecationNote(tt.description)
+			require.Equal(t, tt.expectDeprecated, deprecated)
+			require.Equal(t, tt.expectNote, note)
+		})
+	}
+}
+
+func Test_Deprecations_Inferred(t *testing.T) {
+	var stdout bytes.Buffer
+	explore.PrintInferredDeprecation(&stdout,
+		schema.GroupVersionResource{Group: "resource.k8s.io", Version: "v1beta1", Resource: "resourceclaims"},
+		schema.GroupVersion{Group: "resource.k8s.io", Version: "v1"})
+	require.Equal(t, "DEPRECATED API VERSIONS:\n"+
+		"  resource.k8s.io/v1beta1 resourceclaims\tuse resource.k8s.io/v1\n"+
+		"    inferred: a prerelease version older than the preferred resource.k8s.io/v1\n",
+		stdout.String())
+}
+
+func Test_Run_LiveObject(t *testing.T) {
+	version := k8sVersions[len(k8sVersions)-1]
+	fakeServer, err := clienttestutil.NewFakeOpenAPIV3Server(openAPISpecV3Directories[version])
+	require.NoError(t, err)
+	t.Cleanup(fakeServer.HttpServer.Close)
+	fakeDiscoveryClient := discovery.NewDiscoveryClientForConfigOrDie(&rest.Config{Host: fakeServer.HttpServer.URL})
+	fakeCachedDiscoveryClient := cmdtesting.NewFakeCachedDiscoveryClient()
+	fakeCachedDiscoveryClient.PreferredResources = []*v1.APIResourceList{
+		{
+			GroupVersion: "v1",
+			APIResources: []v1.APIResource{
+				{
+					Name:         "pods",
+					SingularName: "pod",
+					Namespaced:   true,
+					Kind:         "Pod",
+					ShortNames:   []string{"po"},
+				},
+			},
+		},
+	}
+	pod := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "Pod",
+		"metadata": map[string]interface{}{
+			"name":      "my-pod",
+			"namespace": "my-namespace",
+		},
+		"spec": map[string]interface{}{
+			"nodeName": "node-1",
+			"containers": []interface{}{
+				map[string]interface{}{"name": "app", "image": "nginx:1.29"},
+				map[string]interface{}{"name": "sidecar", "image": "busybox:1.37"},
+			},
+		},
+	}}
+	tests := []struct {
+		args           []string
+		output         string
+		expectKeywords []string
+	}{
+		{
+			args: []string{"pod/my-pod", "spec.nodeName$"},
+			expectKeywords: []string{
+				"PATH: pods.spec.nodeName\nVALUE: node-1\n",
+				"KIND:       Pod",
+			},
+		},
+		{
+			args: []string{"pods/my-pod", "spec.containers.image$"},
+			expectKeywords: []string{
+				"VALUE:\n  - nginx:1.29\n  - busybox:1.37\n",
+			},
+		},
+		{
+			args: []string{"po/my-pod", "spec.hostname$"},
+			expectKeywords: []string{
+				"VALUE: <unset>",
+			},
+		},
+		{
+			args:   []string{"pod/my-pod", "spec.nodeName$"},
+			output: "json",
+			expectKeywords: []string{
+				`"value": "node-1"`,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args, " "), func(t *testing.T) {
+			tf := cmdtesting.NewTestFactory().WithNamespace("my-namespace")
+			defer tf.Cleanup()
+			tf.WithDiscoveryClient(fakeCachedDiscoveryClient)
+			tf.OpenAPIV3ClientFunc = func() (openapiclient.Client, error) {
+				return fakeDiscoveryClient.OpenAPIV3(), nil
+			}
+			tf.ClientConfigVal = cmdtesting.DefaultClientConfig()
+			tf.FakeDynamicClient = fakedynamic.NewSimpleDynamicClient(scheme.Scheme, pod)
+			var stdout bytes.Buffer
+			opts := explore.NewOptions(genericclioptions.IOStreams{
+				In:     &bytes.Buffer{},
+				Out:    &stdout,
+				ErrOut: &bytes.Buffer{},
+			})
+			explore.SetCacheDir(opts, t.TempDir())
+			if tt.output != "" {
+				explore.SetOutput(opts, tt.output)
+			}
+			require.NoError(t, opts.Complete(tf, tt.args))
+			require.NoError(t, opts.Run())
+			for _, keyword := range tt.expectKeywords {
+				require.Contains(t, stdout.String(), keyword)
+			}
+		})
+	}
+}
@@ -0,0 +1,108 @@
+package explore
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// identifierRegex matches field names that can be written without quotes in jq.
+var identifierRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// querySegment is a field of a path and whether it is an array or a map,
+// whose keys are not part of the path.
+type querySegment struct {
+	name    string
+	isArray bool
+	isMap   bool
+}
+
+// querySegments splits path.withBrackets into the fields after the resource name.
+// mapPaths is the set of the original paths of the map fields. The last field
+// is never a map to walk, because the path ends at the map itself.
+func querySegments(p path, mapPaths map[string]bool) []querySegment {
+	var segments []querySegment
+	fields := strings.Split(p.original, ".")
+	withBrackets := strings.Split(p.withBrackets, ".")
+	for i, s := range withBrackets[1:] {
+		name, isArray := strings.CutSuffix(s, "[]")
+		// The resource name is the first field.
+		isMap := mapPaths[strings.Join(fields[:i+2], ".")] && i < len(withBrackets)-2
+		segments = append(segments, querySegment{name: name, isArray: isArray, isMap: isMap})
+	}
+	return segments
+}
+
+// jsonPathExpression returns the JSONPath of the path in an object,
+// e.g. .spec.containers[*].image, or .spec.backends.*.host through a map.
+func jsonPathExpression(p path, mapPaths map[string]bool) string {
+	var b strings.Builder
+	for _, s := range querySegments(p, mapPaths) {
+		// e.g. .metadata.annotations.kubectl\.kubernetes\.io/last-applied-configuration
+		b.WriteString("." + strings.ReplaceAll(s.name, ".", `\.`))
+		if s.isArray {
+			b.WriteString("[*]")
+		}
+		if s.isMap {
+			b.WriteString(".*")
+		}
+	}
+	return b.String()
+}
+
+// jqFilter returns the jq filter of the path applied to a list of the resource,
+// e.g. .items[] | .spec.containers[].image, or .items[] | .spec.backends[].host
+// through a map.
+func jqFilter(p path, mapPaths map[string]bool) string {
+	var b strings.Builder
+	for _, s := range querySegments(p, mapPaths) {
+		if identifierRegex.MatchString(s.name) {
+			b.WriteString("." + s.name)
+		} else {
+			b.WriteString(fmt.Sprintf(".%q", s.name))
+		}
+		if s.isArray || s.isMap {
+			b.WriteString("[]")
+		}
+	}
+	return ".items[] | " + b.String()
+}
+
+// customColumnsSpec returns the custom-columns spec showing the name of the
+// object and the path, e.g. NAME:.metadata.name,IMAGE:.spec.containers[*].image.
+func customColumnsSpec(p path, mapPaths map[string]bool) string {
+	segments := querySegments(p, mapPaths)
+	column := strings.ToUpper(segments[len(segments)-1].name)
+	return "NAME:.metadata.name," + column + ":" + jsonPathExpression(p, mapPaths)
+}
+
+// resourceArg returns the resource argument of kubectl get, qualified with
+// the version and the group to get the fields of the explored version.
+func resourceArg(gvr schema.GroupVersionResource) string {
+	if gvr.Group == "" {
+		return gvr.Resource
+	}
+	return gvr.Resource + "." + gvr.Version + "." + gvr.Group
+}
+
+// queryCommand returns the kubectl command querying the path in the output format.
+// mapPaths is the set of the original paths of the map fields of the resource.
+func queryCommand(gvr schema.GroupVersionResource, p path, mapPaths map[string]bool, outputFormat string) (string, error) {
+	if len(querySegments(p, mapPaths)) == 0 {
+		return "", fmt.Errorf("path must contain a field: %s", p.original)
+	}
+	resource := resourceArg(gvr)
+	switch outputFormat {
+	case outputJSONPath:
+		// kubectl get returns a list, so the template goes through its items.
+		return fmt.Sprintf("kubectl get %s -o jsonpath='{.items[*]%s}'", resource, jsonPathExpression(p, mapPaths)), nil
+	case outputCustomColumns:
+		return fmt.Sprintf("kubectl get %s -o custom-columns='%s'", resource, customColumnsSpec(p, mapPaths)), nil
+	case outputJQ:
+		return fmt.Sprintf("kubectl get %s -o json | jq '%s'", resource, jqFilter(p, mapPaths)), nil
+	default:
+		return "", fmt.Errorf("unsupported output format: %s", outputFormat)
+	}
+}
@@ -1,6 +1,8 @@
 package explore
 
 import (
+	"regexp"
+	"slices"
 	"sort"
 	"strings"
 
@@ -20,7 +22,20 @@ func (p path) isEmpty() bool {
 type schemaVisitor struct {
 	prevPath   path
 	pathSchema map[path]proto.Schema
-	err        error
+	// descriptions indexes the description of each path in a single line.
+	// It is populated only if it is not nil.
+	descriptions map[path]string
+	// references indexes the paths referring to each definition, e.g.
+	// io.k8s.api.core.v1.PodSpec.
+	references map[string][]path
+	// required is the set of paths required by the objects declaring them.
+	required map[path]bool
+	// mapPaths is the set of the original paths of the map fields, whose
+	// keys are not part of the paths of their children.
+	mapPaths map[string]bool
+	// filter restricts the paths listed by listPaths by their attributes.
+	filter fieldFilter
+	err    error
 }
 
 var _ proto.SchemaVisitor = (*schemaVisitor)(nil)
@@ -44,6 +59,12 @@ func (v *schemaVisitor) VisitKind(k *proto.Kind) {
 			paths[i].withBrackets += "[]"
 		}
 		v.pathSchema[paths[i]] = schema
+		if slices.Contains(k.RequiredFields, key) {
+			v.required[paths[i]] = true
+		}
+		if v.descriptions != nil {
+			v.descriptions[paths[i]] = strings.Join(strings.Fields(schemaDescription(schema)), " ")
+		}
 		v.prevPath = paths[i]
 		schema.Accept(v)
 	}
@@ -52,6 +73,7 @@ func (v *schemaVisitor) VisitKind(k *proto.Kind) {
 var visitedReferences = map[string]struct{}{}
 
 func (v *schemaVisitor) VisitReference(r proto.Reference) {
+	v.references[r.Reference()] = append(v.references[r.Reference()], v.prevPath)
 	if _, ok := visitedReferences[r.Reference()]; ok {
 		return
 	}
@@ -69,13 +91,16 @@ func (v *schemaVisitor) VisitArray(a *proto.Array) {
 }
 
 func (v *schemaVisitor) VisitMap(m *proto.Map) {
+	v.mapPaths[v.prevPath.original] = true
 	m.SubType.Accept(v)
 }
 
+// listPaths returns the paths matching the attribute filter of the visitor
+// and the given filter, in order.
 func (v *schemaVisitor) listPaths(filter func(path) bool) []path {
 	paths := make([]path, 0, len(v.pathSchema))
 	for path := range v.pathSchema {
-		if filter(path) {
+		if v.filter.match(v, path) && filter(path) {
 			paths = append(paths, path)
 		}
 	}
@@ -84,3 +109,78 @@ func (v *schemaVisitor) listPaths(filter func(path) bool) []path {
 	})
 	return paths
 }
+
+// schemaDescription returns the description of the field, falling back to
+// the description of the definition it refers to.
+func schemaDescription(s proto.Schema) string {
+	if desc := s.GetDescription(); desc != "" {
+		return desc
+	}
+	if r, ok := s.(proto.Reference); ok && r.SubSchema() != nil {
+		return r.SubSchema().GetDescription()
+	}
+	return ""
+}
+
+// fieldFilter narrows the paths down by the attributes of the fields.
+// The zero value matches all the paths.
+type fieldFilter struct {
+	requiredOnly bool
+	// fieldType is the type name shown by kubectl explain, e.g. boolean or []string.
+	fieldType  string
+	hasEnum    bool
+	deprecated bool
+	readOnly   bool
+}
+
+var (
+	// deprecatedRegex matches the conventions of the Kubernetes API, e.g.
+	// "Deprecated: Use X instead." or "This field is deprecated", but not an
+	// enum value mentioned as deprecated, e.g. "Recycle (deprecated)".
+	deprecatedRegex = regexp.MustCompile(`(?i)(^|[.;]\s+|\n\s*)deprecated\b|\bis deprecated\b|\bdeprecated:`)
+	// readOnlyRegex matches the conventions of the Kubernetes API, e.g.
+	// "Populated by the system. Read-only.", but not "Mounted read-only if true".
+	readOnlyRegex = regexp.MustCompile(`(?i)\bread-only\.|populated by the system`)
+)
+
+func (f fieldFilter) match(v *schemaVisitor, p path) bool {
+	s := v.pathSchema[p]
+	if f.requiredOnly && !v.required[p] {
+		return false
+	}
+	if f.fieldType != "" && !strings.EqualFold(explain.GetTypeName(s), f.fieldType) {
+		return false
+	}
+	if f.hasEnum && len(schemaEnum(s)) == 0 {
+		return false
+	}
+	if f.deprecated && !isDeprecated(s) {
+		return false
+	}
+	if f.readOnly && !readOnlyRegex.MatchString(schemaDescription(s)) {
+		return false
+	}
+	return true
+}
+
+// isDeprecated reports whether the description of the field says it is deprecated.
+func isDeprecated(s proto.Schema) bool {
+	return deprecatedRegex.MatchString(schemaDescription(s))
+}
+
+// schemaEnum returns the enum of the field, falling back to the enum of the
+// definition it refers to, or of the items of an array. See normalizeForProto for where it is kept.
+func schemaEnum(s proto.Schema) []interface{} {
+	// An array of enum values carries the enum in its items.
+	if a, ok := s.(*proto.Array); ok {
+		return schemaEnum(a.SubType)
+	}
+	if enum, ok := s.GetExtensions()[enumExtension].([]interface{}); ok {
+		return enum
+	}
+	if r, ok := s.(proto.Reference); ok && r.SubSchema() != nil {
+		enum, _ := r.SubSchema().GetExtensions()[enumExtension].([]interface{})
+		return enum
+	}
+	return nil
+}
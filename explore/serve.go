@@ -0,0 +1,320 @@
+package explore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/explain"
+)
+
+type ServeOptions struct {
+	*Options
+
+	// User input
+	port int
+}
+
+func NewServeOptions(o *Options) *ServeOptions {
+	return &ServeOptions{Options: o, port: 8080}
+}
+
+func newServeCmd(o *Options, f cmdutil.Factory) *cobra.Command {
+	s := NewServeOptions(o)
+	cmd := &cobra.Command{
+		Use:   "serve [--port PORT]",
+		Short: "Browse the fields of all API resources in a web browser.",
+		Example: `
+# Browse the fields at http://localhost:8080.
+kubectl explore serve
+
+# Link to a field.
+kubectl explore serve --port 8081
+open http://localhost:8081/apps/v1/deployments/spec.template.spec.containers.image
+`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			cmdutil.CheckErr(s.Complete(f))
+			cmdutil.CheckErr(s.Run())
+		},
+	}
+	cmd.Flags().IntVar(&s.port, "port", s.port, "Port to listen on at localhost")
+	cmd.Flags().StringSliceVarP(&o.filenames, "filename", "f", o.filenames, "Browse CustomResourceDefinitions in the files or directories instead of a cluster")
+	return cmd
+}
+
+func (s *ServeOptions) Complete(f cmdutil.Factory) error {
+	return s.completeDependencies(f)
+}
+
+func (s *ServeOptions) Run() error {
+	address := net.JoinHostPort("localhost", strconv.Itoa(s.port))
+	fmt.Fprintf(s.Out, "Serving on http://%s\n", address)
+	return http.ListenAndServe(address, s.handler())
+}
+
+// coreGroup is the group segment of URLs for the resources in the core group,
+// which has no name.
+const coreGroup = "core"
+
+// schemaServer renders the resources at / and the fields of a resource at
+// /<group>/<version>/<resource>/<path>.
+type schemaServer struct {
+	o *Options
+	// mu serializes walking schemas, which share visitedReferences.
+	mu        sync.Mutex
+	visitors  map[schema.GroupVersionResource]*schemaVisitor
+	documents *openAPIV3Documents
+}
+
+func (s *ServeOptions) handler() http.Handler {
+	return &schemaServer{
+		o:         s.Options,
+		visitors:  make(map[schema.GroupVersionResource]*schemaVisitor),
+		documents: newOpenAPIV3Documents(s.cachedOpenAPIV3Client),
+	}
+}
+
+func (s *schemaServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	segments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	var b bytes.Buffer
+	var err error
+	switch {
+	case r.URL.Path == "/":
+		err = s.renderIndex(&b)
+	case len(segments) == 3 || len(segments) == 4:
+		group := segments[0]
+		if group == coreGroup {
+			group = ""
+		}
+		gvr := schema.GroupVersionResource{Group: group, Version: segments[1], Resource: segments[2]}
+		var fieldPath string
+		if len(segments) == 4 {
+			fieldPath = segments[3]
+		}
+		err = s.renderResource(&b, gvr, fieldPath)
+	default:
+		err = errNotFound
+	}
+	if errors.Is(err, errNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(b.Bytes())
+}
+
+var errNotFound = errors.New("not found")
+
+// resourceURL returns the URL of the resource, or of the field if the path is not empty.
+func resourceURL(gvr schema.GroupVersionResource, fieldPath string) string {
+	group := gvr.Group
+	if group == "" {
+		group = coreGroup
+	}
+	u := "/" + group + "/" + gvr.Version + "/" + gvr.Resource
+	if fieldPath != "" {
+		u += "/" + fieldPath
+	}
+	return u
+}
+
+type indexGroup struct {
+	Name      string
+	Resources []indexResource
+}
+
+type indexResource struct {
+	Name    string
+	Version string
+	URL     string
+}
+
+func (s *schemaServer) renderIndex(b *bytes.Buffer) error {
+	gvrs, err := s.o.listGVRs()
+	if err != nil {
+		return err
+	}
+	var groups []indexGroup
+	for _, gvr := range gvrs {
+		name := gvr.Group
+		if name == "" {
+			name = coreGroup
+		}
+		if len(groups) == 0 || groups[len(groups)-1].Name != name {
+			groups = append(groups, indexGroup{Name: name})
+		}
+		g := &groups[len(groups)-1]
+		g.Resources = append(g.Resources, indexResource{Name: gvr.Resource, Version: gvr.Version, URL: resourceURL(gvr, "")})
+	}
+	slices.SortStableFunc(groups, func(a, b indexGroup) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return indexTemplate.Execute(b, groups)
+}
+
+// treeNode is a field in the tree of a resource.
+type treeNode struct {
+	Name     string
+	Type     string
+	URL      string
+	Path     string
+	Open     bool
+	Selected bool
+	Children []*treeNode
+}
+
+type resourcePage struct {
+	Resource    string
+	GVR         string
+	Path        string
+	Explanation string
+	Fields      []*treeNode
+}
+
+func (s *schemaServer) renderResource(b *bytes.Buffer, gvr schema.GroupVersionResource, fieldPath string) error {
+	if _, err := s.o.mapper.KindFor(gvr); err != nil {
+		return errNotFound
+	}
+	visitor, ok := s.visitors[gvr]
+	if !ok {
+		var err error
+		visitor, err = s.o.visit(gvr)
+		if err != nil {
+			return err
+		}
+		s.visitors[gvr] = visitor
+	}
+	resource := strings.ToLower(gvr.Resource)
+	selected := path{original: resource, withBrackets: resource}
+	root := &treeNode{}
+	nodes := map[string]*treeNode{"": root}
+	for _, p := range visitor.listPaths(func(path) bool { return true }) {
+		fields := strings.TrimPrefix(p.original, resource+".")
+		parent, name := "", fields
+		if i := strings.LastIndex(fields, "."); i >= 0 {
+			parent, name = fields[:i], fields[i+1:]
+		}
+		node := &treeNode{
+			Name: name,
+			Type: explain.GetTypeName(visitor.pathSchema[p]),
+			URL:  resourceURL(gvr, fields),
+			Path: fields,
+		}
+		nodes[fields] = node
+		if n, ok := nodes[parent]; ok {
+			n.Children = append(n.Children, node)
+		}
+		if fields == fieldPath {
+			selected = p
+		}
+	}
+	if fieldPath != "" {
+		node, ok := nodes[fieldPath]
+		if !ok {
+			return errNotFound
+		}
+		node.Selected = true
+		// Open the ancestors of the selected field.
+		for p := fieldPath; p != ""; p = parentPath(p) {
+			nodes[p].Open = true
+		}
+	}
+	e := explainer{
+		gvr:                 gvr,
+		openAPIV3Client:     s.o.cachedOpenAPIV3Client,
+		documents:           s.documents,
+		enablePrintPath:     true,
+		enablePrintBrackets: s.o.showBrackets,
+	}
+	var explanation bytes.Buffer
+	if err := e.explain(&explanation, selected); err != nil {
+		return err
+	}
+	return resourceTemplate.Execute(b, resourcePage{
+		Resource:    resource,
+		GVR:         gvr.String(),
+		Path:        fieldPath,
+		Explanation: explanation.String(),
+		Fields:      root.Children,
+	})
+}
+
+const pageStyle = `
+body { font-family: sans-serif; margin: 0; display: flex; height: 100vh; }
+nav { width: 40%; overflow: auto; padding: 1em; border-right: 1px solid #ddd; }
+main { flex: 1; overflow: auto; padding: 1em; }
+input { width: 100%; box-sizing: border-box; margin-bottom: 1em; }
+details > :not(summary) { margin-left: 1.2em; }
+.leaf { margin-left: 1.2em; display: block; }
+.type { color: #888; }
+.selected { font-weight: bold; }
+pre { white-space: pre-wrap; }
+`
+
+// filterScript hides the entries that do not match the search and opens the
+// ancestors of the ones that do.
+const filterScript = `
+document.getElementById("search").addEventListener("input", (e) => {
+  const q = e.target.value.toLowerCase();
+  const matches = (el) => el.dataset.path.toLowerCase().includes(q);
+  document.querySelectorAll("[data-path]").forEach((el) => {
+    const visible = q === "" || matches(el) || Array.from(el.querySelectorAll("[data-path]")).some(matches);
+    el.hidden = !visible;
+    if (el.tagName === "DETAILS" && q !== "") el.open = visible;
+  });
+});
+`
+
+var indexTemplate = template.Must(template.New("index").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>kubectl explore</title><style>` + pageStyle + `</style></head>
+<body>
+<nav>
+<input id="search" type="search" placeholder="Search resources" autofocus>
+{{range .}}<details open data-path="{{.Name}}"><summary>{{.Name}}</summary>
+{{range .Resources}}<a class="leaf" data-path="{{.Name}}" href="{{.URL}}">{{.Name}} <span class="type">{{.Version}}</span></a>
+{{end}}</details>
+{{end}}</nav>
+<main><p>Select a resource to browse its fields.</p></main>
+<script>` + filterScript + `</script>
+</body>
+</html>
+`))
+
+var resourceTemplate = template.Must(template.New("resource").Parse(`{{define "node"}}{{if .Children}}<details data-path="{{.Path}}"{{if .Open}} open{{end}}><summary><a href="{{.URL}}"{{if .Selected}} class="selected"{{end}}>{{.Name}}</a> <span class="type">&lt;{{.Type}}&gt;</span></summary>
+{{range .Children}}{{template "node" .}}{{end}}</details>
+{{else}}<a class="leaf{{if .Selected}} selected{{end}}" data-path="{{.Path}}" href="{{.URL}}">{{.Name}} <span class="type">&lt;{{.Type}}&gt;</span></a>
+{{end}}{{end}}<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>{{.Resource}}{{with .Path}}.{{.}}{{end}} - kubectl explore</title><style>` + pageStyle + `</style></head>
+<body>
+<nav>
+<p><a href="/">All resources</a> / {{.GVR}}</p>
+<input id="search" type="search" placeholder="Search fields" autofocus>
+{{range .Fields}}{{template "node" .}}{{end}}</nav>
+<main><pre>{{.Explanation}}</pre></main>
+<script>` + filterScript + `</script>
+</body>
+</html>
+`))
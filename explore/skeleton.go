@@ -0,0 +1,239 @@
+package explore
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// skeleton is a minimal manifest containing the fields leading to the paths added to it.
+type skeleton struct {
+	doc  openAPIV3Document
+	kind map[string]interface{}
+	root *yaml.Node
+	// annotate comments the last field of each path with its type and description.
+	annotate bool
+	// comments are placed by encode because where a comment goes depends on
+	// the value, which may become a block by adding another path.
+	comments map[*yaml.Node]string
+}
+
+func newSkeleton(doc openAPIV3Document, gvk schema.GroupVersionKind) (*skeleton, error) {
+	kind, err := doc.lookupKind(gvk)
+	if err != nil {
+		return nil, err
+	}
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	appendField(root, "apiVersion", stringNode(gvk.GroupVersion().String()))
+	appendField(root, "kind", stringNode(gvk.Kind))
+	metadata := &yaml.Node{Kind: yaml.MappingNode}
+	appendField(metadata, "name", stringNode(""))
+	appendField(root, "metadata", metadata)
+	return &skeleton{
+		doc:      doc,
+		kind:     kind,
+		root:     root,
+		comments: make(map[*yaml.Node]string),
+	}, nil
+}
+
+// add adds the fields leading to the path, using path.withBrackets to tell
+// which of them are lists, and a placeholder of the type of the last field.
+func (s *skeleton) add(p path) error {
+	segments := strings.Split(p.withBrackets, ".")[1:]
+	if len(segments) == 0 {
+		return fmt.Errorf("path must contain a field: %s", p.original)
+	}
+	fields := strings.Split(p.original, ".")[1:]
+	current := s.root
+	for i, segment := range segments {
+		name, isList := strings.CutSuffix(segment, "[]")
+		field, _, err := s.doc.lookupField(s.kind, fields[:i+1])
+		if err != nil {
+			return err
+		}
+		key, value := lookupFieldNode(current, name)
+		if i == len(segments)-1 {
+			if value == nil {
+				key = stringNode(name)
+				current.Content = append(current.Content, key, s.placeholder(field))
+			}
+			if s.annotate {
+				s.comments[key] = fmt.Sprintf("<%s> %s", s.doc.typeName(field), firstSentence(s.doc.description(field)))
+			}
+			return nil
+		}
+		if value == nil {
+			value = &yaml.Node{Kind: yaml.MappingNode}
+			if isList {
+				value = &yaml.Node{Kind: yaml.SequenceNode}
+			}
+			appendField(current, name, value)
+		}
+		if isList {
+			value = ensureFirstItem(value)
+		} else {
+			ensureMapping(value)
+		}
+		// The fields of a map value are nested under an arbitrary key.
+		if resolved := s.doc.resolve(field); resolved["properties"] == nil && resolved["additionalProperties"] != nil {
+			_, inner := lookupFieldNode(value, "key")
+			if inner == nil {
+				inner = &yaml.Node{Kind: yaml.MappingNode}
+				appendField(value, "key", inner)
+			}
+			ensureMapping(inner)
+			value = inner
+		}
+		current = value
+	}
+	return fmt.Errorf("path must contain a field: %s", p.original)
+}
+
+func (s *skeleton) encode(w io.Writer) error {
+	s.placeComments(s.root)
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(s.root); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// placeComments puts the comment of a field after its value if the value is
+// in a line, e.g. "replicas: 0 # comment", otherwise after its key.
+func (s *skeleton) placeComments(n *yaml.Node) {
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, value := n.Content[i], n.Content[i+1]
+			if comment, ok := s.comments[key]; ok {
+				if value.Kind == yaml.ScalarNode || len(value.Content) == 0 {
+					value.LineComment = comment
+				} else {
+					key.LineComment = comment
+				}
+			}
+		}
+	}
+	for _, child := range n.Content {
+		s.placeComments(child)
+	}
+}
+
+// placeholder returns the zero value of the type of the field,
+// or the first value of its enum.
+func (s *skeleton) placeholder(field map[string]interface{}) *yaml.Node {
+	resolved := s.doc.resolve(field)
+	if items, ok := resolved["items"].(map[string]interface{}); ok {
+		return &yaml.Node{Kind: yaml.SequenceNode, Content: []*yaml.Node{s.placeholder(items)}}
+	}
+	enum, _ := field["enum"].([]interface{})
+	if enum == nil {
+		enum, _ = resolved["enum"].([]interface{})
+	}
+	if len(enum) > 0 {
+		if v, ok := enum[0].(string); ok {
+			return stringNode(v)
+		}
+	}
+	switch placeholderType(resolved) {
+	case "string":
+		return stringNode("")
+	case "integer", "number":
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: "0"}
+	case "boolean":
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: "false"}
+	default:
+		return &yaml.Node{Kind: yaml.MappingNode, Style: yaml.FlowStyle}
+	}
+}
+
+// placeholderType returns the type of the schema, or the first type of a
+// scalar without a type, e.g. integer for IntOrString and string for Quantity,
+// which are not objects.
+func placeholderType(s map[string]interface{}) string {
+	if t, ok := s["type"].(string); ok {
+		return t
+	}
+	if s["format"] == "int-or-string" || s["x-kubernetes-int-or-string"] == true {
+		return "integer"
+	}
+	var types []string
+	for _, key := range []string{"oneOf", "anyOf"} {
+		schemas, _ := s[key].([]interface{})
+		for _, v := range schemas {
+			sub, _ := v.(map[string]interface{})
+			t, _ := sub["type"].(string)
+			if !slices.Contains([]string{"string", "integer", "number", "boolean"}, t) {
+				return ""
+			}
+			types = append(types, t)
+		}
+	}
+	if len(types) == 0 {
+		return ""
+	}
+	return types[0]
+}
+
+func stringNode(v string) *yaml.Node {
+	n := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}
+	if v == "" {
+		n.Style = yaml.DoubleQuotedStyle
+	}
+	return n
+}
+
+func appendField(mapping *yaml.Node, name string, value *yaml.Node) {
+	mapping.Content = append(mapping.Content, stringNode(name), value)
+}
+
+// lookupFieldNode returns the key and the value of the field in the mapping,
+// or nil if it does not exist.
+func lookupFieldNode(mapping *yaml.Node, name string) (key, value *yaml.Node) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == name {
+			return mapping.Content[i], mapping.Content[i+1]
+		}
+	}
+	return nil, nil
+}
+
+// ensureMapping turns a placeholder such as {} into a mapping to add fields to.
+func ensureMapping(n *yaml.Node) {
+	if n.Kind != yaml.MappingNode {
+		*n = yaml.Node{Kind: yaml.MappingNode}
+	}
+	n.Style = 0
+}
+
+// ensureFirstItem turns n into a list and returns its first item as a mapping.
+func ensureFirstItem(n *yaml.Node) *yaml.Node {
+	if n.Kind != yaml.SequenceNode {
+		*n = yaml.Node{Kind: yaml.SequenceNode}
+	}
+	if len(n.Content) == 0 {
+		n.Content = append(n.Content, &yaml.Node{Kind: yaml.MappingNode})
+	}
+	ensureMapping(n.Content[0])
+	return n.Content[0]
+}
+
+// maxCommentLength is the maximum number of characters of a description in a comment.
+const maxCommentLength = 100
+
+// firstSentence returns the first sentence of the description in a line.
+func firstSentence(desc string) string {
+	desc = strings.Join(strings.Fields(desc), " ")
+	if i := strings.Index(desc, ". "); i >= 0 {
+		desc = desc[:i+1]
+	}
+	if r := []rune(desc); len(r) > maxCommentLength {
+		desc = string(r[:maxCommentLength]) + "…"
+	}
+	return desc
+}
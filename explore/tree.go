@@ -0,0 +1,231 @@
+package explore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/ktr0731/go-fuzzyfinder"
+)
+
+const (
+	treeMarkerCollapsed = "▸ "
+	treeMarkerExpanded  = "▾ "
+	treeMarkerLeaf      = "  "
+)
+
+// fieldNode is a resource or a field in the tree mode.
+type fieldNode struct {
+	name     string
+	path     path
+	depth    int
+	parent   *fieldNode
+	children []*fieldNode
+	explainer
+}
+
+// buildFieldTree arranges the paths under their resources. Fields leading to
+// the paths are added even if they are filtered out, to keep the hierarchy.
+func buildFieldTree(pathExplainers map[path]explainer, paths []path) []*fieldNode {
+	var roots []*fieldNode
+	nodes := make(map[string]*fieldNode)
+	for _, p := range paths {
+		originals := strings.Split(p.original, ".")
+		withBrackets := strings.Split(p.withBrackets, ".")
+		var parent *fieldNode
+		for i := range originals {
+			original := strings.Join(originals[:i+1], ".")
+			node, ok := nodes[original]
+			if !ok {
+				node = &fieldNode{
+					name: originals[i],
+					path: path{
+						original:     original,
+						withBrackets: strings.Join(withBrackets[:i+1], "."),
+					},
+					depth:     i,
+					parent:    parent,
+					explainer: pathExplainers[p],
+				}
+				nodes[original] = node
+				if parent == nil {
+					roots = append(roots, node)
+				} else {
+					parent.children = append(parent.children, node)
+				}
+			}
+			parent = node
+		}
+	}
+	return roots
+}
+
+// label returns the name indented by the depth, with the marker of whether
+// the node is expanded.
+func (n *fieldNode) label(expanded bool) string {
+	marker := treeMarkerLeaf
+	if len(n.children) > 0 {
+		marker = treeMarkerCollapsed
+		if expanded {
+			marker = treeMarkerExpanded
+		}
+	}
+	return strings.Repeat("  ", n.depth) + marker + n.name
+}
+
+// visibleFieldNodes returns the nodes under the expanded nodes in the order of the tree.
+func visibleFieldNodes(roots []*fieldNode, expanded map[*fieldNode]bool) []*fieldNode {
+	var nodes []*fieldNode
+	var walk func([]*fieldNode)
+	walk = func(children []*fieldNode) {
+		for _, n := range children {
+			nodes = append(nodes, n)
+			if expanded[n] {
+				walk(n.children)
+			}
+		}
+	}
+	walk(roots)
+	return nodes
+}
+
+// fieldTree is the state of the tree mode, the expanded nodes and the node
+// whose subtree the finder is scoped to, which is nil for the whole tree.
+type fieldTree struct {
+	roots    []*fieldNode
+	expanded map[*fieldNode]bool
+	scope    *fieldNode
+}
+
+// newFieldTree scopes the tree to the only resource if there is one.
+func newFieldTree(roots []*fieldNode) *fieldTree {
+	t := &fieldTree{roots: roots, expanded: make(map[*fieldNode]bool)}
+	if len(roots) == 1 {
+		t.expanded[roots[0]] = true
+		t.scope = roots[0]
+	}
+	return t
+}
+
+// items returns the items of the finder scoped to the subtree, ".." and "."
+// followed by the visible nodes under the scope, and the nodes.
+func (t *fieldTree) items() ([]string, []*fieldNode) {
+	var items []string
+	nodes := visibleFieldNodes(t.roots, t.expanded)
+	if t.scope != nil {
+		items = []string{drillUpLabel, drillPrintLabel}
+		nodes = visibleFieldNodes(t.scope.children, t.expanded)
+	}
+	for _, n := range nodes {
+		items = append(items, n.label(t.expanded[n]))
+	}
+	return items, nodes
+}
+
+// selectItem applies the item selected in the finder and returns the node to
+// print, if any. ".." scopes the finder to the parent, "." prints the node
+// scoped to, a collapsed node is expanded and scoped to, an expanded node is
+// collapsed, and a node without children is printed.
+func (t *fieldTree) selectItem(nodes []*fieldNode, idx int) *fieldNode {
+	if t.scope != nil {
+		switch idx {
+		case 0:
+			t.scope = t.scope.parent
+			return nil
+		case 1:
+			return t.scope
+		}
+		idx -= 2
+	}
+	n := nodes[idx]
+	switch {
+	case len(n.children) == 0:
+		return n
+	case t.expanded[n]:
+		t.expanded[n] = false
+	default:
+		t.expanded[n] = true
+		t.scope = n
+	}
+	return nil
+}
+
+// runTree lets the user expand and collapse the fields with Enter until a
+// field is printed. Expanding a field scopes the fuzzy search to its subtree,
+// and ".." or Esc goes back up to the parent.
+func (o *Options) runTree(pathExplainers map[path]explainer, paths []path) error {
+	t := newFieldTree(buildFieldTree(pathExplainers, paths))
+	var selected *fieldNode
+	for {
+		scope := t.scope
+		items, nodes := t.items()
+		// The fuzzy finder lists the first item at the bottom, so the items
+		// are reversed to show the tree from the top.
+		reversed := slices.Clone(items)
+		slices.Reverse(reversed)
+		index := func(i int) int { return len(items) - 1 - i }
+		// node returns the node of the item, which is the node scoped to for "." and "..".
+		node := func(i int) *fieldNode {
+			if scope == nil {
+				return nodes[i]
+			}
+			if i < 2 {
+				return scope
+			}
+			return nodes[i-2]
+		}
+		header := "Enter: expand or collapse a field, or print a field without children"
+		if scope != nil {
+			header = breadcrumb(scope.path, o.showBrackets) + "\n" + header + ", ..: go up, .: print this field, Esc: go up"
+		}
+		opts := []fuzzyfinder.Option{
+			fuzzyfinder.WithHeader(header),
+			fuzzyfinder.WithCursorPosition(fuzzyfinder.CursorPositionTop),
+			fuzzyfinder.WithPreviewWindow(func(i, _, _ int) string {
+				if i < 0 {
+					return ""
+				}
+				i = index(i)
+				if scope != nil && i == 0 {
+					if scope.parent == nil {
+						return "Go up to the resources"
+					}
+					return fmt.Sprintf("Go up to %s", breadcrumb(scope.parent.path, o.showBrackets))
+				}
+				n := node(i)
+				var w bytes.Buffer
+				if err := n.explain(&w, n.path); err != nil {
+					return fmt.Sprintf("preview is broken: %s", err)
+				}
+				return w.String()
+			}),
+		}
+		if selected != nil {
+			opts = append(opts, fuzzyfinder.WithPreselected(func(i int) bool {
+				i = index(i)
+				return (scope == nil || i >= 2) && node(i) == selected
+			}))
+		}
+		idx, err := fuzzyfinder.Find(reversed, func(i int) string { return reversed[i] }, opts...)
+		if errors.Is(err, fuzzyfinder.ErrAbort) && scope != nil {
+			// Esc goes up like "..".
+			idx, err = index(0), nil
+		}
+		if err != nil {
+			return err
+		}
+		idx = index(idx)
+		if n := t.selectItem(nodes, idx); n != nil {
+			return n.print(o.Out, n.path)
+		}
+		// The field the finder goes back from is selected to continue from it.
+		selected = nil
+		if scope != nil && idx == 0 {
+			selected = scope
+		} else if t.scope == scope {
+			selected = node(idx)
+		}
+	}
+}
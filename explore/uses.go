@@ -0,0 +1,120 @@
+package explore
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+)
+
+type UsesOptions struct {
+	*Options
+
+	// User input
+	definition string
+}
+
+func NewUsesOptions(o *Options) *UsesOptions {
+	return &UsesOptions{Options: o}
+}
+
+func newUsesCmd(o *Options, f cmdutil.Factory) *cobra.Command {
+	u := NewUsesOptions(o)
+	cmd := &cobra.Command{
+		Use:   "uses DEFINITION",
+		Short: "List the resources and the fields where an OpenAPI definition is embedded.",
+		Example: `
+# List the fields of all resources that are a PodSpec.
+kubectl explore uses io.k8s.api.core.v1.PodSpec
+
+# The name can be shortened to its last segments.
+kubectl explore uses Volume
+kubectl explore uses core.v1.PodTemplateSpec
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			cmdutil.CheckErr(u.Complete(f, args))
+			cmdutil.CheckErr(u.Run())
+		},
+	}
+	cmd.Flags().StringSliceVarP(&o.filenames, "filename", "f", o.filenames, "Search CustomResourceDefinitions in the files or directories instead of a cluster")
+	return cmd
+}
+
+func (u *UsesOptions) Complete(f cmdutil.Factory, args []string) error {
+	u.definition = args[0]
+	return u.completeDependencies(f)
+}
+
+// definitionUsage is a path of a resource where a definition is embedded.
+type definitionUsage struct {
+	definition string
+	gvr        schema.GroupVersionResource
+	path       path
+}
+
+func (u *UsesOptions) Run() error {
+	_, gvrs, err := u.discover()
+	if err != nil {
+		return err
+	}
+	var usages []definitionUsage
+	for _, gvr := range gvrs {
+		visitor, err := u.visit(gvr)
+		if err != nil {
+			return fmt.Errorf("%s: %w", gvr, err)
+		}
+		for definition, paths := range visitor.references {
+			if !matchDefinition(definition, u.definition) {
+				continue
+			}
+			for _, p := range paths {
+				usages = append(usages, definitionUsage{definition: definition, gvr: gvr, path: p})
+			}
+		}
+	}
+	if len(usages) == 0 {
+		return fmt.Errorf("no resources use %q", u.definition)
+	}
+	printUsages(u.Out, usages, u.showBrackets)
+	return nil
+}
+
+// matchDefinition reports whether the name is the definition or its last
+// segments, e.g. PodSpec and core.v1.PodSpec for io.k8s.api.core.v1.PodSpec.
+func matchDefinition(definition, name string) bool {
+	return definition == name || strings.HasSuffix(definition, "."+name)
+}
+
+// printUsages prints the paths grouped by the definition and the resource.
+func printUsages(w io.Writer, usages []definitionUsage, showBrackets bool) {
+	slices.SortStableFunc(usages, func(a, b definitionUsage) int {
+		if c := strings.Compare(a.definition, b.definition); c != 0 {
+			return c
+		}
+		if c := strings.Compare(resourceArg(a.gvr), resourceArg(b.gvr)); c != 0 {
+			return c
+		}
+		return strings.Compare(a.path.original, b.path.original)
+	})
+	for i, usage := range usages {
+		if i == 0 || usage.definition != usages[i-1].definition {
+			if i > 0 {
+				fmt.Fprintln(w)
+			}
+			fmt.Fprintf(w, "DEFINITION: %s\n", usage.definition)
+		}
+		if i == 0 || usage.definition != usages[i-1].definition || usage.gvr != usages[i-1].gvr {
+			fmt.Fprintf(w, "\n%s:\n", resourceArg(usage.gvr))
+		}
+		p := usage.path.original
+		if showBrackets {
+			p = usage.path.withBrackets
+		}
+		fmt.Fprintf(w, "  %s\n", p)
+	}
+}
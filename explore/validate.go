@@ -0,0 +1,318 @@
+package explore
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/kube-openapi/pkg/util/proto"
+	cmdutil "k8s.io/kubectl/pkg/cmd/util"
+	"k8s.io/kubectl/pkg/explain"
+)
+
+type ValidateOptions struct {
+	*Options
+}
+
+func NewValidateOptions(o *Options) *ValidateOptions {
+	return &ValidateOptions{Options: o}
+}
+
+func newValidateCmd(o *Options, f cmdutil.Factory) *cobra.Command {
+	v := NewValidateOptions(o)
+	cmd := &cobra.Command{
+		Use:   "validate -f FILENAME",
+		Short: "Report the unknown fields, type mismatches and missing required fields of manifests.",
+		Example: `
+# Validate a manifest against the schema of the cluster.
+kubectl explore validate -f ./deployment.yaml
+
+# Validate custom resources against the CustomResourceDefinitions next to them without a cluster.
+kubectl explore validate -f ./config/crd/bases/ -f ./config/samples/
+`,
+		Args: cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			cmdutil.CheckErr(v.Complete(f))
+			cmdutil.CheckErr(v.Run())
+		},
+	}
+	cmd.Flags().StringSliceVarP(&o.filenames, "filename", "f", o.filenames, "Manifests to validate, and CustomResourceDefinitions to validate them against instead of a cluster")
+	_ = cmd.MarkFlagRequired("filename")
+	return cmd
+}
+
+func (v *ValidateOptions) Complete(f cmdutil.Factory) error {
+	if len(v.filenames) == 0 {
+		return fmt.Errorf("-f is required")
+	}
+	if err := v.completeDependencies(f); err != nil {
+		return err
+	}
+	if len(v.manifests) == 0 {
+		return fmt.Errorf("no manifest to validate found in %s", strings.Join(v.filenames, ", "))
+	}
+	return v.completeManifests()
+}
+
+func (v *ValidateOptions) Run() error {
+	var problems int
+	for _, gvr := range v.objectGVRs() {
+		visitor, err := v.visit(gvr)
+		if err != nil {
+			return err
+		}
+		s, err := v.resourceSchema(gvr)
+		if err != nil {
+			return err
+		}
+		for _, object := range v.objects[gvr] {
+			val := &manifestValidator{paths: visitor.listPaths(func(path) bool { return true })}
+			resource := strings.ToLower(gvr.Resource)
+			val.validate(object, s, path{original: resource, withBrackets: resource})
+			problems += len(val.problems)
+			if err := printProblems(v.Out, gvr, object, val.problems, v.showBrackets); err != nil {
+				return err
+			}
+		}
+	}
+	if problems > 0 {
+		return fmt.Errorf("%d problems found", problems)
+	}
+	return nil
+}
+
+type problemKind int
+
+const (
+	problemUnknownField problemKind = iota
+	problemTypeMismatch
+	problemMissingRequired
+)
+
+// problem is a field of a manifest that does not conform to the schema.
+type problem struct {
+	kind problemKind
+	path path
+	// suggestion is the nearest valid path of an unknown field.
+	suggestion path
+	expected   string
+	got        string
+}
+
+// manifestValidator walks a manifest along the schema of its kind.
+type manifestValidator struct {
+	// paths are the valid paths to suggest for unknown fields.
+	paths    []path
+	problems []problem
+}
+
+// validate checks the value against the schema at the path. The items of an
+// array and the values of a map share the path of the field, as in schemaVisitor.
+func (m *manifestValidator) validate(value interface{}, s proto.Schema, p path) {
+	if value == nil {
+		return
+	}
+	if r, ok := s.(proto.Reference); ok {
+		s = r.SubSchema()
+	}
+	switch s := s.(type) {
+	case *proto.Array:
+		items, ok := value.([]interface{})
+		if !ok {
+			m.mismatch(p, s, value)
+			return
+		}
+		for _, item := range items {
+			m.validate(item, s.SubType, p)
+		}
+	case *proto.Map:
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			m.mismatch(p, s, value)
+			return
+		}
+		for _, key := range sortedKeys(fields) {
+			m.validate(fields[key], s.SubType, p)
+		}
+	case *proto.Kind:
+		fields, ok := value.(map[string]interface{})
+		if !ok {
+			m.mismatch(p, s, value)
+			return
+		}
+		for _, required := range s.RequiredFields {
+			if _, ok := fields[required]; !ok {
+				m.add(problem{kind: problemMissingRequired, path: childPath(p, required, s.Fields[required])})
+			}
+		}
+		preserveUnknownFields, _ := s.GetExtensions()["x-kubernetes-preserve-unknown-fields"].(bool)
+		for _, key := range sortedKeys(fields) {
+			field, ok := s.Fields[key]
+			if !ok {
+				if !preserveUnknownFields {
+					unknown := childPath(p, key, nil)
+					m.add(problem{kind: problemUnknownField, path: unknown, suggestion: m.nearest(unknown)})
+				}
+				continue
+			}
+			m.validate(fields[key], field, childPath(p, key, field))
+		}
+	case *proto.Primitive:
+		if !primitiveMatches(s, value) {
+			m.mismatch(p, s, value)
+		}
+	}
+}
+
+func (m *manifestValidator) mismatch(p path, s proto.Schema, value interface{}) {
+	m.add(problem{kind: problemTypeMismatch, path: p, expected: explain.GetTypeName(s), got: jsonTypeName(value)})
+}
+
+// add records the problem once, since the items of an array share their paths.
+func (m *manifestValidator) add(pr problem) {
+	for _, existing := range m.problems {
+		if existing == pr {
+			return
+		}
+	}
+	m.problems = append(m.problems, pr)
+}
+
+// nearest returns the valid path with the smallest edit distance to the path.
+func (m *manifestValidator) nearest(p path) path {
+	var nearest path
+	minDistance := math.MaxInt
+	for _, candidate := range m.paths {
+		if d := levenshtein(p.original, candidate.original); d < minDistance {
+			nearest, minDistance = candidate, d
+		}
+	}
+	return nearest
+}
+
+func childPath(p path, key string, s proto.Schema) path {
+	child := path{
+		original:     p.original + "." + key,
+		withBrackets: p.withBrackets + "." + key,
+	}
+	if _, ok := s.(*proto.Array); ok {
+		child.withBrackets += "[]"
+	}
+	return child
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// primitiveMatches reports whether the value decoded from JSON is of the primitive type.
+func primitiveMatches(s *proto.Primitive, value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		return s.Type == proto.String
+	case bool:
+		return s.Type == proto.Boolean
+	case float64:
+		switch s.Type {
+		case proto.Number:
+			return true
+		case proto.Integer:
+			return v == math.Trunc(v)
+		}
+		return s.Type == proto.String && s.Format == "int-or-string"
+	case int64:
+		return s.Type == proto.Integer || s.Type == proto.Number
+	}
+	return false
+}
+
+func jsonTypeName(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case int64:
+		return "integer"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "Object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// levenshtein returns the number of single character edits to change a into b.
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
+
+// printProblems prints the problems of the manifest under its resource and name,
+// e.g. deployments.apps/nginx.
+func printProblems(w io.Writer, gvr schema.GroupVersionResource, object map[string]interface{}, problems []problem, showBrackets bool) error {
+	name := (&unstructured.Unstructured{Object: object}).GetName()
+	heading := gvr.GroupResource().String() + "/" + name
+	if len(problems) == 0 {
+		_, err := fmt.Fprintf(w, "%s: valid\n", heading)
+		return err
+	}
+	format := func(p path) string {
+		if showBrackets {
+			return p.withBrackets
+		}
+		return p.original
+	}
+	if _, err := fmt.Fprintf(w, "%s:\n", heading); err != nil {
+		return err
+	}
+	for _, pr := range problems {
+		var msg string
+		switch pr.kind {
+		case problemUnknownField:
+			msg = "unknown field"
+			if !pr.suggestion.isEmpty() {
+				msg += fmt.Sprintf(", did you mean %s?", format(pr.suggestion))
+			}
+		case problemTypeMismatch:
+			msg = fmt.Sprintf("expected <%s>, got <%s>", pr.expected, pr.got)
+		case problemMissingRequired:
+			msg = "missing required field"
+		}
+		if _, err := fmt.Fprintf(w, "  %s: %s\n", format(pr.path), msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}